@@ -0,0 +1,109 @@
+package ammo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultLogFormat matches a typical nginx access_log combined format.
+const DefaultLogFormat = `$remote_addr - - [$time] "$method $path $proto"`
+
+var logFormatVarRE = regexp.MustCompile(`\$(\w+)`)
+
+// AccessLog reads ammo from an access log file, extracting $method and
+// $path from each line according to Format (DefaultLogFormat if empty).
+type AccessLog struct {
+	Filename       string
+	Format         string
+	Scheme, Target string
+
+	re *regexp.Regexp
+}
+
+// NewAccessLog compiles Format into a matcher and returns the ready-to-use
+// provider, or an error if Format doesn't define $method and $path.
+func NewAccessLog(filename, format, scheme, target string) (*AccessLog, error) {
+	if format == "" {
+		format = DefaultLogFormat
+	}
+	re, err := compileLogFormat(format)
+	if err != nil {
+		return nil, fmt.Errorf("bad -log-format: %s", err)
+	}
+	for _, name := range []string{"method", "path"} {
+		if re.SubexpIndex(name) < 0 {
+			return nil, fmt.Errorf("-log-format must include $%s", name)
+		}
+	}
+	return &AccessLog{Filename: filename, Format: format, Scheme: scheme, Target: target, re: re}, nil
+}
+
+// compileLogFormat turns a template like DefaultLogFormat into a regexp
+// with one named capture group per $placeholder, matched against the start
+// of each line (anything after the template is ignored). A placeholder directly
+// inside "..." or [...] captures everything up to the closing delimiter
+// (so e.g. nginx's $time_local, which itself contains a space, is captured
+// whole); any other placeholder captures a single non-space token.
+func compileLogFormat(format string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteByte('^')
+	rest := format
+	for {
+		loc := logFormatVarRE.FindStringIndex(rest)
+		if loc == nil {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		lit := rest[:loc[0]]
+		name := logFormatVarRE.FindStringSubmatch(rest[loc[0]:loc[1]])[1]
+		b.WriteString(regexp.QuoteMeta(lit))
+		switch {
+		case strings.HasSuffix(lit, `"`):
+			fmt.Fprintf(&b, `(?P<%s>[^"]+)`, name)
+		case strings.HasSuffix(lit, `[`):
+			fmt.Fprintf(&b, `(?P<%s>[^\]]+)`, name)
+		default:
+			fmt.Fprintf(&b, `(?P<%s>\S+)`, name)
+		}
+		rest = rest[loc[1]:]
+	}
+	return regexp.Compile(b.String())
+}
+
+// Run implements Provider.
+func (a *AccessLog) Run(ctx context.Context, reqs chan<- *Request) error {
+	f, err := os.Open(a.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	methodIdx := a.re.SubexpIndex("method")
+	pathIdx := a.re.SubexpIndex("path")
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		m := a.re.FindStringSubmatch(line)
+		if m == nil {
+			// TODO: check method and path validity
+			continue
+		}
+		url := fmt.Sprintf("%s://%s%s", a.Scheme, a.Target, m[pathIdx])
+		req, err := http.NewRequest(m[methodIdx], url, nil)
+		if err != nil {
+			return fmt.Errorf("can't construct request: %s", err)
+		}
+		select {
+		case reqs <- &Request{Request: req}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,74 @@
+package ammo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewAccessLogRequiresMethodAndPath(t *testing.T) {
+	if _, err := NewAccessLog("ammo.log", `$remote_addr - - [$time]`, "http", "example.com"); err == nil {
+		t.Fatal("expected error for a format missing $method/$path")
+	}
+}
+
+func TestAccessLogDefaultFormat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	content := `127.0.0.1 - - [10/Oct/2023:13:55:36 +0000] "GET /foo/bar HTTP/1.1"` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewAccessLog(path, "", "http", "example.com")
+	if err != nil {
+		t.Fatalf("NewAccessLog: %v", err)
+	}
+
+	reqs := make(chan *Request, 1)
+	if err := a.Run(context.Background(), reqs); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(reqs)
+
+	req := <-reqs
+	if req == nil {
+		t.Fatal("expected a request to be parsed from the access log line")
+	}
+	if req.Method != "GET" {
+		t.Errorf("Method = %q, want GET", req.Method)
+	}
+	if req.URL.String() != "http://example.com/foo/bar" {
+		t.Errorf("URL = %q, want http://example.com/foo/bar", req.URL.String())
+	}
+}
+
+func TestAccessLogSkipsUnmatchedLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	content := "not a log line\n" +
+		`127.0.0.1 - - [10/Oct/2023:13:55:36 +0000] "GET /ok HTTP/1.1"` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewAccessLog(path, "", "http", "example.com")
+	if err != nil {
+		t.Fatalf("NewAccessLog: %v", err)
+	}
+
+	reqs := make(chan *Request, 2)
+	if err := a.Run(context.Background(), reqs); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(reqs)
+
+	var got []*Request
+	for req := range reqs {
+		got = append(got, req)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1 (the malformed line should be skipped)", len(got))
+	}
+}
@@ -0,0 +1,70 @@
+// Package ammo provides pluggable sources of HTTP requests ("ammo" in
+// Yandex Tank parlance) for the load generator to fire.
+package ammo
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Request pairs an http.Request with the metadata a Provider may know
+// about it, such as a tag used to group results in reports.
+type Request struct {
+	*http.Request
+	Tag string
+}
+
+// Provider reads ammo from a source and constructs Requests from it,
+// sending them to reqs until the source is exhausted or ctx is done.
+type Provider interface {
+	Run(ctx context.Context, reqs chan<- *Request) error
+}
+
+// emptyPassBackoff bounds how often Loop re-runs Provider when a pass
+// produces no requests at all, so a misconfigured or empty source degrades
+// instead of pegging a CPU core.
+const emptyPassBackoff = 100 * time.Millisecond
+
+// Loop repeatedly re-runs Provider until ctx is done, so a finite ammo
+// source doesn't stall a long test partway through.
+type Loop struct {
+	Provider Provider
+}
+
+// Run implements Provider.
+func (l *Loop) Run(ctx context.Context, reqs chan<- *Request) error {
+	for {
+		pass := make(chan *Request)
+		forwarded := make(chan int, 1)
+		go func() {
+			n := 0
+			for req := range pass {
+				n++
+				select {
+				case reqs <- req:
+				case <-ctx.Done():
+				}
+			}
+			forwarded <- n
+		}()
+		err := l.Provider.Run(ctx, pass)
+		close(pass)
+		n := <-forwarded
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		if n == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(emptyPassBackoff):
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package ammo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// JSONLines reads ammo as one JSON object per line:
+// {"method","url","headers","body_b64","tag"}. method defaults to GET.
+type JSONLines struct {
+	Filename string
+}
+
+type jsonLine struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	BodyB64 string            `json:"body_b64"`
+	Tag     string            `json:"tag"`
+}
+
+// Run implements Provider.
+func (j *JSONLines) Run(ctx context.Context, reqs chan<- *Request) error {
+	f, err := os.Open(j.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var a jsonLine
+		if err := json.Unmarshal(line, &a); err != nil {
+			return fmt.Errorf("can't parse ammo line: %s", err)
+		}
+
+		method := a.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+
+		var body *bytes.Reader
+		if a.BodyB64 != "" {
+			b, err := base64.StdEncoding.DecodeString(a.BodyB64)
+			if err != nil {
+				return fmt.Errorf("can't decode body_b64: %s", err)
+			}
+			body = bytes.NewReader(b)
+		}
+
+		var req *http.Request
+		if body != nil {
+			req, err = http.NewRequest(method, a.URL, body)
+		} else {
+			req, err = http.NewRequest(method, a.URL, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("can't construct request: %s", err)
+		}
+		for k, v := range a.Headers {
+			req.Header.Set(k, v)
+		}
+
+		select {
+		case reqs <- &Request{Request: req, Tag: a.Tag}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,65 @@
+package ammo
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJSONLinesDecodesBodyAndHeaders(t *testing.T) {
+	// body_b64 is base64("hello"), i.e. "aGVsbG8="
+	path := writeTempFile(t, "ammo.jsonl",
+		`{"method":"POST","url":"http://example.com/x","headers":{"X-Tag":"v"},"body_b64":"aGVsbG8=","tag":"t1"}`+"\n"+
+			`{"url":"http://example.com/y"}`+"\n")
+
+	j := &JSONLines{Filename: path}
+	reqs := make(chan *Request, 2)
+	if err := j.Run(context.Background(), reqs); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(reqs)
+
+	first := <-reqs
+	if first.Method != "POST" {
+		t.Errorf("Method = %q, want POST", first.Method)
+	}
+	if first.Tag != "t1" {
+		t.Errorf("Tag = %q, want t1", first.Tag)
+	}
+	if got := first.Header.Get("X-Tag"); got != "v" {
+		t.Errorf("X-Tag header = %q, want v", got)
+	}
+	body, err := io.ReadAll(first.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(body) != "hello" {
+		t.Errorf("body = %q, want %q", body, "hello")
+	}
+
+	second := <-reqs
+	if second.Method != "GET" {
+		t.Errorf("Method = %q, want GET (default)", second.Method)
+	}
+}
+
+func TestJSONLinesBadBodyB64Errors(t *testing.T) {
+	path := writeTempFile(t, "ammo.jsonl", `{"url":"http://example.com","body_b64":"not-valid-base64!"}`+"\n")
+
+	j := &JSONLines{Filename: path}
+	reqs := make(chan *Request, 1)
+	if err := j.Run(context.Background(), reqs); err == nil {
+		t.Fatal("expected an error decoding invalid base64")
+	}
+}
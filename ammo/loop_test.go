@@ -0,0 +1,88 @@
+package ammo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fixedProvider sends n requests per Run call, for exercising Loop.
+type fixedProvider struct {
+	n int
+}
+
+func (f *fixedProvider) Run(ctx context.Context, reqs chan<- *Request) error {
+	for i := 0; i < f.n; i++ {
+		select {
+		case reqs <- &Request{}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestLoopRepeatsUntilCtxDone(t *testing.T) {
+	l := &Loop{Provider: &fixedProvider{n: 2}}
+	reqs := make(chan *Request)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errc := make(chan error, 1)
+	go func() { errc <- l.Run(ctx, reqs) }()
+
+	for i := 0; i < 5; i++ {
+		select {
+		case <-reqs:
+		case <-time.After(time.Second):
+			t.Fatal("expected Loop to keep re-running Provider")
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was canceled")
+	}
+}
+
+// TestLoopBacksOffOnEmptyPass checks Loop doesn't spin a CPU core re-running
+// a Provider that produces nothing (e.g. an empty ammo source), by bounding
+// how many times it can re-run Provider within emptyPassBackoff.
+func TestLoopBacksOffOnEmptyPass(t *testing.T) {
+	empty := &fixedProvider{n: 0}
+	var runs int
+	counting := providerFunc(func(ctx context.Context, reqs chan<- *Request) error {
+		runs++
+		return empty.Run(ctx, reqs)
+	})
+
+	l := &Loop{Provider: counting}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- l.Run(ctx, make(chan *Request)) }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-errc:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was canceled")
+	}
+
+	if runs > 2 {
+		t.Errorf("Provider.Run was re-run %d times in 50ms, want backoff to bound it to ~1", runs)
+	}
+}
+
+type providerFunc func(ctx context.Context, reqs chan<- *Request) error
+
+func (f providerFunc) Run(ctx context.Context, reqs chan<- *Request) error {
+	return f(ctx, reqs)
+}
@@ -0,0 +1,77 @@
+package ammo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Raw reads ammo in Yandex phantom's "size header" format: each record
+// starts with a line "<size>[ <tag>]" followed by exactly <size> bytes of
+// a raw HTTP request (request line, headers, and optional body).
+type Raw struct {
+	Filename       string
+	Scheme, Target string
+}
+
+// Run implements Provider.
+func (r *Raw) Run(ctx context.Context, reqs chan<- *Request) error {
+	f, err := os.Open(r.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	for {
+		header, err := br.ReadString('\n')
+		if err == io.EOF && header == "" {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		header = strings.TrimRight(header, "\r\n")
+		if header == "" {
+			if err == io.EOF {
+				return nil
+			}
+			continue
+		}
+
+		parts := strings.SplitN(header, " ", 2)
+		size, convErr := strconv.Atoi(parts[0])
+		if convErr != nil {
+			return fmt.Errorf("bad ammo size header %q: %s", header, convErr)
+		}
+		var tag string
+		if len(parts) > 1 {
+			tag = parts[1]
+		}
+
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return fmt.Errorf("can't read %d byte ammo chunk: %s", size, err)
+		}
+
+		req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(chunk)))
+		if err != nil {
+			return fmt.Errorf("can't parse raw ammo: %s", err)
+		}
+		req.RequestURI = ""
+		req.URL.Scheme = r.Scheme
+		req.URL.Host = r.Target
+
+		select {
+		case reqs <- &Request{Request: req, Tag: tag}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
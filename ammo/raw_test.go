@@ -0,0 +1,51 @@
+package ammo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestRawParsesSizeHeaderFramedRequests(t *testing.T) {
+	first := "GET /foo HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	second := "POST /bar HTTP/1.1\r\nHost: example.com\r\nContent-Length: 5\r\n\r\nhello"
+
+	content := fmt.Sprintf("%d tag1\n%s%d\n%s", len(first), first, len(second), second)
+	path := writeTempFile(t, "ammo.raw", content)
+
+	r := &Raw{Filename: path, Scheme: "http", Target: "example.com"}
+	reqs := make(chan *Request, 2)
+	if err := r.Run(context.Background(), reqs); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(reqs)
+
+	firstReq := <-reqs
+	if firstReq.Method != "GET" || firstReq.URL.Path != "/foo" {
+		t.Errorf("first request = %s %s, want GET /foo", firstReq.Method, firstReq.URL.Path)
+	}
+	if firstReq.Tag != "tag1" {
+		t.Errorf("Tag = %q, want tag1", firstReq.Tag)
+	}
+	if firstReq.URL.String() != "http://example.com/foo" {
+		t.Errorf("URL = %q, want http://example.com/foo", firstReq.URL.String())
+	}
+
+	secondReq := <-reqs
+	if secondReq.Method != "POST" || secondReq.URL.Path != "/bar" {
+		t.Errorf("second request = %s %s, want POST /bar", secondReq.Method, secondReq.URL.Path)
+	}
+	if secondReq.Tag != "" {
+		t.Errorf("Tag = %q, want empty", secondReq.Tag)
+	}
+}
+
+func TestRawRejectsBadSizeHeader(t *testing.T) {
+	path := writeTempFile(t, "ammo.raw", "notanumber\nwhatever\n")
+
+	r := &Raw{Filename: path, Scheme: "http", Target: "example.com"}
+	reqs := make(chan *Request, 1)
+	if err := r.Run(context.Background(), reqs); err == nil {
+		t.Fatal("expected an error for a malformed size header")
+	}
+}
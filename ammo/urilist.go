@@ -0,0 +1,49 @@
+package ammo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// URIList reads ammo as one URL per line, all fired with the same Method
+// (GET if unset).
+type URIList struct {
+	Filename string
+	Method   string
+}
+
+// Run implements Provider.
+func (u *URIList) Run(ctx context.Context, reqs chan<- *Request) error {
+	f, err := os.Open(u.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	method := u.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		url := strings.TrimSpace(s.Text())
+		if url == "" {
+			continue
+		}
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			return fmt.Errorf("can't construct request: %s", err)
+		}
+		select {
+		case reqs <- &Request{Request: req}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return s.Err()
+}
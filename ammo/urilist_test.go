@@ -0,0 +1,59 @@
+package ammo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestURIListDefaultsToGETAndSkipsBlankLines(t *testing.T) {
+	path := writeTempFile(t, "ammo.uris", "http://example.com/foo\n\nhttp://example.com/bar\n")
+
+	u := &URIList{Filename: path}
+	reqs := make(chan *Request, 2)
+	if err := u.Run(context.Background(), reqs); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(reqs)
+
+	var got []*Request
+	for req := range reqs {
+		got = append(got, req)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d requests, want 2 (the blank line should be skipped)", len(got))
+	}
+	for _, req := range got {
+		if req.Method != "GET" {
+			t.Errorf("Method = %q, want GET (default)", req.Method)
+		}
+	}
+	if got[0].URL.String() != "http://example.com/foo" {
+		t.Errorf("URL = %q, want http://example.com/foo", got[0].URL.String())
+	}
+}
+
+func TestURIListUsesConfiguredMethod(t *testing.T) {
+	path := writeTempFile(t, "ammo.uris", "http://example.com/foo\n")
+
+	u := &URIList{Filename: path, Method: "POST"}
+	reqs := make(chan *Request, 1)
+	if err := u.Run(context.Background(), reqs); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	close(reqs)
+
+	req := <-reqs
+	if req.Method != "POST" {
+		t.Errorf("Method = %q, want POST", req.Method)
+	}
+}
+
+func TestURIListRejectsMalformedURL(t *testing.T) {
+	path := writeTempFile(t, "ammo.uris", "://not-a-url\n")
+
+	u := &URIList{Filename: path}
+	reqs := make(chan *Request, 1)
+	if err := u.Run(context.Background(), reqs); err == nil {
+		t.Fatal("expected an error for a malformed URL")
+	}
+}
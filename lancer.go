@@ -1,75 +1,196 @@
-package lancer // import "github.com/ei-grad/lancer"
+package main
 
 import (
 	"context"
-	"errors"
-	"math"
+	"fmt"
+	"log"
+	"sync"
 	"time"
-)
 
-var (
-	ErrNoReceiver  = errors.New("no ready receiver on channel")
-	ErrTickMissed  = errors.New("tick time has been missed")
-	ErrBadDuration = errors.New("duration is not enought to send any requests")
+	"github.com/ei-grad/lancer/schedule"
 )
 
-// Linear generates ticks with linearly increasing count of ticks per second
-// from low to high during specified duration. Ticks are sent to the lance
-// channel. Use ctx to stop the tick generation if needed.
-func Linear(
-	ctx context.Context,
-	lance chan time.Duration,
-	low float64,
-	high float64,
-	duration time.Duration,
-) error {
-
-	if duration <= 0 {
-		return ErrBadDuration
-	}
+// LancerStats is a snapshot of a Lancer's progress, safe to read while Run
+// is still in flight.
+type LancerStats struct {
+	Issued    int
+	Missed    int
+	Skipped   int
+	TargetRPS float64
+}
 
-	//if ctx == nil {
-	//	ctx = context.Background()
-	//}
+// Lancer paces ticks according to a schedule.Schedule and hands them out to
+// a pool of workers over a single internally-owned channel, so the channel
+// is only ever closed by the one goroutine that owns it (Run), never by
+// main racing against the workers that still read from it.
+type Lancer struct {
+	sched      schedule.Schedule
+	missedFrac int
 
-	var (
-		durationSeconds = float64(duration) / float64(time.Second)
-		lowSq           = low * low
-		slope           = (high - low) / durationSeconds
-	)
+	lance chan time.Duration
+	done  chan struct{}
 
-	tickTime := func(n int) time.Duration {
-		if slope == 0 {
-			return time.Duration(float64(n*int(time.Second)) / low)
-		}
-		ret := (math.Sqrt(lowSq+2*slope*float64(n)) - low) / slope
-		return time.Duration(ret * float64(time.Second))
+	// workersGone is closed the moment the worker count drops back to
+	// zero, so Run can abort instead of blocking forever on a lance send
+	// nobody will ever read.
+	workersGone     chan struct{}
+	workersGoneOnce sync.Once
+
+	mu      sync.Mutex
+	stats   LancerStats
+	workers int
+}
+
+// NewLancer returns a Lancer that paces itself off sched, aborting the run
+// if more than a 1/missedFrac fraction of ticks come due too late to fire.
+func NewLancer(sched schedule.Schedule, missedFrac int) *Lancer {
+	return &Lancer{
+		sched:       sched,
+		missedFrac:  missedFrac,
+		lance:       make(chan time.Duration),
+		done:        make(chan struct{}),
+		workersGone: make(chan struct{}),
+	}
+}
+
+// AddWorker registers a worker with the Lancer, returning the channel it
+// should receive ticks from and a stop function to call (typically via
+// defer) once the worker stops reading from it. The returned channel is
+// closed once Run has issued its last tick, so ranging over it or checking
+// ok on receive are both safe ways for a worker to notice the run ending.
+//
+// If every registered worker calls stop before Run is done issuing ticks
+// (e.g. the ammo source ran out without -loop), Run aborts instead of
+// blocking forever trying to hand a tick to nobody.
+func (l *Lancer) AddWorker(ctx context.Context) (<-chan time.Duration, func()) {
+	l.mu.Lock()
+	l.workers++
+	l.mu.Unlock()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.workers--
+			stuck := l.workers == 0
+			l.mu.Unlock()
+			if stuck {
+				l.workersGoneOnce.Do(func() { close(l.workersGone) })
+				select {
+				case <-l.done:
+				default:
+					log.Printf("lancer: last worker stopped before the run finished")
+				}
+			}
+		})
 	}
+	return l.lance, stop
+}
+
+// Done is closed once Run returns, whether because the schedule completed,
+// ctx was canceled, or the missed-tick budget was exceeded.
+func (l *Lancer) Done() <-chan struct{} {
+	return l.done
+}
+
+// Stats returns the current issued/missed/skipped counters and the
+// instantaneous target RPS implied by the schedule.
+func (l *Lancer) Stats() LancerStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Run drives ticks according to the schedule, sending them to the channel
+// returned by AddWorker, until ctx is done or the schedule is exhausted.
+//
+// sched.Count() < 0 marks an unbounded schedule (e.g. schedule.Instances):
+// missed-tick accounting is skipped, and Run instead runs until ctx is done
+// or, if sched implements schedule.Bounded, its Duration elapses.
+func (l *Lancer) Run(ctx context.Context) error {
+	defer close(l.done)
+	defer close(l.lance)
 
-	if tickTime(1) > duration {
-		return ErrBadDuration
+	count := l.sched.Count()
+
+	var deadline <-chan time.Time
+	if count < 0 {
+		if b, ok := l.sched.(schedule.Bounded); ok {
+			timer := time.NewTimer(b.Duration())
+			defer timer.Stop()
+			deadline = timer.C
+		}
 	}
 
-	count := int((high + low) * durationSeconds / 2)
 	start := time.Now()
 
-	for i := 1; i < count+1; i++ {
-		nextTick := tickTime(i)
-		dt := start.Add(nextTick).Sub(time.Now())
-		//if dt < 0 {
-		//	return ErrTickMissed
-		//}
+	if !l.issue(ctx, deadline, time.Duration(0)) {
+		return nil
+	}
+
+	for i := 1; count < 0 || i < count+1; i++ {
+		tickTime := l.sched.Next(i)
+		l.setTargetRPS(l.sched.Next(i) - l.sched.Next(i-1))
+		dt := start.Add(tickTime).Sub(time.Now())
+		if dt < 0 {
+			if count > 0 {
+				l.recordMissed()
+				rps := float64(time.Second) / float64(l.sched.Next(i)-l.sched.Next(i-1))
+				log.Printf("missed %s for lance near %.1f RPS", dt, rps)
+				if l.missedFrac*l.missed() > count {
+					return fmt.Errorf("max missed fraction reached at %.1f RPS", rps)
+				}
+			}
+			continue
+		}
 		if dt > time.Millisecond {
 			time.Sleep(dt)
 		}
-		select {
-		case <-ctx.Done():
-			return context.Canceled
-		case lance <- nextTick:
-		default:
-			return ErrNoReceiver
+		if !l.issue(ctx, deadline, tickTime) {
+			return nil
 		}
 	}
-
 	return nil
 }
+
+// issue sends tick to the lance channel, reporting whether it was actually
+// delivered; a false return means ctx was done, the deadline fired, or the
+// last worker stopped reading, in which case this one tick is counted as
+// skipped; the ticks the schedule had left after it are not counted.
+func (l *Lancer) issue(ctx context.Context, deadline <-chan time.Time, tick time.Duration) bool {
+	select {
+	case l.lance <- tick:
+		l.mu.Lock()
+		l.stats.Issued++
+		l.mu.Unlock()
+		return true
+	case <-ctx.Done():
+	case <-deadline:
+	case <-l.workersGone:
+	}
+	l.mu.Lock()
+	l.stats.Skipped++
+	l.mu.Unlock()
+	return false
+}
+
+func (l *Lancer) recordMissed() {
+	l.mu.Lock()
+	l.stats.Missed++
+	l.mu.Unlock()
+}
+
+func (l *Lancer) missed() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats.Missed
+}
+
+func (l *Lancer) setTargetRPS(tickDelta time.Duration) {
+	if tickDelta <= 0 {
+		return
+	}
+	rps := float64(time.Second) / float64(tickDelta)
+	l.mu.Lock()
+	l.stats.TargetRPS = rps
+	l.mu.Unlock()
+}
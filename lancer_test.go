@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ei-grad/lancer/schedule"
+)
+
+// TestLancerAbortsWhenWorkersGone reproduces a worker pool that runs out of
+// ammo (no -loop) well before the schedule's tick count is reached: once
+// the only worker stops reading ticks, Run must give up instead of blocking
+// forever on a lance send nobody will ever receive.
+func TestLancerAbortsWhenWorkersGone(t *testing.T) {
+	sched := schedule.NewConst(1000, 10*time.Second)
+	l := NewLancer(sched, 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lance, stop := l.AddWorker(ctx)
+
+	errc := make(chan error, 1)
+	go func() { errc <- l.Run(ctx) }()
+
+	for i := 0; i < 3; i++ {
+		<-lance
+	}
+	stop()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the last worker stopped")
+	}
+}
+
+// TestLancerRunFinishesSchedule checks the ordinary case still works: with
+// a worker draining ticks throughout, Run completes once the schedule's
+// tick count is exhausted.
+func TestLancerRunFinishesSchedule(t *testing.T) {
+	// A slower-paced schedule and realistic missedFrac: the test only
+	// cares that Run finishes, not that scheduling is jitter-free, and a
+	// handful of missed ticks from ordinary goroutine scheduling (or a
+	// busy CI runner) shouldn't fail it.
+	sched := schedule.NewConst(100, time.Second)
+	l := NewLancer(sched, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lance, stop := l.AddWorker(ctx)
+	defer stop()
+
+	done := make(chan struct{})
+	go func() {
+		for range lance {
+		}
+		close(done)
+	}()
+
+	errc := make(chan error, 1)
+	go func() { errc <- l.Run(ctx) }()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Run did not finish the schedule in time")
+	}
+
+	<-done
+	stats := l.Stats()
+	if stats.Issued == 0 {
+		t.Fatalf("expected some ticks to be issued, got %+v", stats)
+	}
+}
@@ -1,193 +1,425 @@
 package main
 
 import (
-	"bufio"
 	"context"
-	"errors"
 	"flag"
 	"fmt"
-	"golang.org/x/sync/errgroup"
 	"io/ioutil"
 	"log"
-	"math"
 	"net/http"
+	"net/http/httptrace"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ei-grad/lancer/ammo"
+	"github.com/ei-grad/lancer/report"
+	"github.com/ei-grad/lancer/schedule"
+	"github.com/ei-grad/lancer/transport"
+	"golang.org/x/sync/errgroup"
 )
 
-// Parse access.log file, construct http.Request objects and put them to
-// spears channel
-func Parse(ctx context.Context, filename, scheme, target string, spears chan *http.Request, cancelRequestsOnStop bool) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
+// ammoProviderFromFlag builds the Provider named by -ammo-type.
+func ammoProviderFromFlag(ammoType, filename, logFormat, scheme, target string) (ammo.Provider, error) {
+	switch ammoType {
+	case "access-log":
+		return ammo.NewAccessLog(filename, logFormat, scheme, target)
+	case "json":
+		return &ammo.JSONLines{Filename: filename}, nil
+	case "raw":
+		return &ammo.Raw{Filename: filename, Scheme: scheme, Target: target}, nil
+	case "uri-list":
+		return &ammo.URIList{Filename: filename}, nil
+	default:
+		return nil, fmt.Errorf("unknown -ammo-type %q", ammoType)
 	}
-	defer f.Close()
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		line := s.Text()
-		parts := strings.Split(line, " ")
-		// TODO: check method and path validity
-		method := parts[5][1:]
-		path := parts[6]
-		url := fmt.Sprintf("%s://%s%s", scheme, target, path)
-		req, err := http.NewRequest(method, url, nil)
-		if cancelRequestsOnStop {
-			req = req.WithContext(ctx)
-		}
+}
+
+// transportFlags holds the -fail-rate/-latency-inject/-drop-rate/-fault-host/
+// -fault-path settings used to build a fault-injecting RoundTripper.
+type transportFlags struct {
+	failRate      float64
+	latencyInject time.Duration
+	dropRate      float64
+	faultHost     string
+	faultPath     string
+}
+
+// roundTripperFromFlag returns http.DefaultTransport unless tf configures
+// any fault injection, in which case it returns a *transport.FaultyTransport
+// wrapping it.
+func roundTripperFromFlag(tf transportFlags) (http.RoundTripper, error) {
+	if tf.failRate == 0 && tf.latencyInject == 0 && tf.dropRate == 0 {
+		return http.DefaultTransport, nil
+	}
+	ft := &transport.FaultyTransport{
+		Base:          http.DefaultTransport,
+		FailRate:      tf.failRate,
+		LatencyInject: tf.latencyInject,
+		DropRate:      tf.dropRate,
+	}
+	if tf.faultHost != "" {
+		re, err := regexp.Compile(tf.faultHost)
 		if err != nil {
-			return fmt.Errorf("can't construct request: %s", err)
-		}
-		select {
-		case spears <- req:
-		case <-ctx.Done():
-			return nil
+			return nil, fmt.Errorf("bad -fault-host: %s", err)
 		}
+		ft.HostFilter = re
 	}
-	if s.Err() != nil {
-		return s.Err()
+	if tf.faultPath != "" {
+		re, err := regexp.Compile(tf.faultPath)
+		if err != nil {
+			return nil, fmt.Errorf("bad -fault-path: %s", err)
+		}
+		ft.PathFilter = re
 	}
-	return nil
+	return ft, nil
 }
 
-// Lancer generates linearly increasing load of HTTP requests
-type Lancer struct {
-	low, high float64
-	duration  time.Duration
-
-	lowSq, slope, durationSeconds float64
-
-	missedFrac int
+// requestTrace collects the httptrace.ClientTrace callback timestamps for a
+// single request so Worker can derive per-phase durations after it
+// completes.
+type requestTrace struct {
+	dnsStart, connectStart, connAcquired time.Time
+	wroteRequest, firstByte              time.Time
+	dnsErr, connectErr                   error
 }
 
-// NewLancer creates a new Lancer object
-func NewLancer(low, high float64, duration time.Duration, missedFrac int) *Lancer {
-	durationSeconds := float64(duration) / float64(time.Second)
-	return &Lancer{
-		low:             low,
-		high:            high,
-		duration:        duration,
-		lowSq:           low * low,
-		slope:           (high - low) / durationSeconds,
-		durationSeconds: durationSeconds,
-		missedFrac:      missedFrac,
+func (rt *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { rt.dnsStart = time.Now() },
+		DNSDone:  func(di httptrace.DNSDoneInfo) { rt.dnsErr = di.Err },
+		ConnectStart: func(network, addr string) {
+			rt.connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			rt.connectErr = err
+		},
+		GotConn:              func(httptrace.GotConnInfo) { rt.connAcquired = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { rt.wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { rt.firstByte = time.Now() },
 	}
 }
 
-func (l *Lancer) tickTime(n int) time.Duration {
-	if l.slope == 0 {
-		return time.Duration(float64(n*int(time.Second)) / l.low)
+// connectTime returns how long DNS lookup, TCP connect and TLS handshake
+// took together, or 0 if the connection was reused and none of that
+// happened.
+func (rt *requestTrace) connectTime() time.Duration {
+	if rt.connectStart.IsZero() {
+		return 0
+	}
+	start := rt.dnsStart
+	if start.IsZero() {
+		start = rt.connectStart
 	}
-	ret := (math.Sqrt(l.lowSq+2*l.slope*float64(n)) - l.low) / l.slope
-	return time.Duration(ret * float64(time.Second))
+	end := rt.connAcquired
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(start)
 }
 
-// Lance starts a load simulation with sending ticks to lance channel.
-func (l *Lancer) Lance(ctx context.Context, lance chan time.Duration) error {
-	var missed int
-	count := int((l.high + l.low) * l.durationSeconds / 2)
-	start := time.Now()
-	select {
-	case lance <- time.Duration(0):
-	case <-ctx.Done():
-		return nil
+// sendTime returns how long it took to write the request once a
+// connection was available.
+func (rt *requestTrace) sendTime(start time.Time) time.Duration {
+	if rt.wroteRequest.IsZero() {
+		return 0
 	}
-	for i := 1; i < count+1; i++ {
-		tickTime := l.tickTime(i)
-		dt := start.Add(tickTime).Sub(time.Now())
-		if dt < 0 {
-			missed++
-			rps := float64(time.Second) / float64(l.tickTime(i)-l.tickTime(i-1))
-			log.Printf("missed %s for lance near %.1f RPS", dt, rps)
-			if l.missedFrac*missed > count {
-				return fmt.Errorf("max missed fraction reached at %.1f RPS", rps)
-			}
-			continue
-		}
-		if dt > time.Millisecond {
-			time.Sleep(dt)
-		}
-		select {
-		case lance <- tickTime:
-		case <-ctx.Done():
-			return nil
-		}
+	base := rt.connAcquired
+	if base.IsZero() {
+		base = start
 	}
-	return nil
+	return rt.wroteRequest.Sub(base)
 }
 
-// Hit contains info about request timings, sizes and statuses
-// TODO: add ConnectTime, SendTime, ReceiveTime, SizeOut, NetCode
-type Hit struct {
-	Path              string
-	Tick, TotalTime   time.Duration
-	SizeIn, ProtoCode int
-	Error             error
+// receiveTime returns how long it took to read the response body after the
+// first byte arrived.
+func (rt *requestTrace) receiveTime(end time.Time) time.Duration {
+	if rt.firstByte.IsZero() {
+		return 0
+	}
+	return end.Sub(rt.firstByte)
 }
 
-var readyWorkers chan int
+// netCode classifies a completed attempt for the phout/influx exports.
+func (rt *requestTrace) netCode(ctx context.Context, err error) report.NetCode {
+	switch {
+	case rt.dnsErr != nil:
+		return report.NetCodeDNSFail
+	case rt.connectErr != nil:
+		return report.NetCodeConnectFail
+	case err != nil && ctx.Err() != nil:
+		return report.NetCodeTimeout
+	case err != nil:
+		return report.NetCodeConnectFail
+	default:
+		return report.NetCodeOK
+	}
+}
 
-// Worker sends an http.Requests coming from spears channel
-func Worker(ctx context.Context, spears chan *http.Request,
-	lance chan time.Duration, hits chan Hit) error {
+// Worker sends the ammo.Requests coming from spears channel, pacing itself
+// off lancer and sending each request with rt. hardCtx governs the lifetime
+// of in-flight requests: once it's done, any RoundTrip in progress is
+// canceled immediately, regardless of ctx (which only stops the worker from
+// picking up further work).
+func Worker(ctx, hardCtx context.Context, spears chan *ammo.Request,
+	lancer *Lancer, hits chan report.Hit, rt http.RoundTripper) error {
+	lance, stop := lancer.AddWorker(ctx)
+	defer stop()
 	for spear := range spears {
 		var tick time.Duration
-		readyWorkers <- 1
 		select {
-		case tick = <-lance:
+		case t, ok := <-lance:
+			if !ok {
+				return nil
+			}
+			tick = t
 		case <-ctx.Done():
 			return nil
 		}
-		readyWorkers <- -1
 		t := time.Now()
-		// TODO: use httptrace module to get additional info
-		resp, err := http.DefaultTransport.RoundTrip(spear)
+
+		req := spear.Request.WithContext(hardCtx)
+		trace := &requestTrace{}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+		sizeOut := int(req.ContentLength)
+		if sizeOut < 0 {
+			sizeOut = 0
+		}
+
+		resp, err := rt.RoundTrip(req)
 		if err == nil {
 			var body []byte
 			body, err = ioutil.ReadAll(resp.Body)
 			resp.Body.Close()
+			now := time.Now()
 			if err == nil {
-				hits <- Hit{
-					Tick:      tick,
-					Path:      spear.URL.Path,
-					ProtoCode: resp.StatusCode,
-					TotalTime: time.Now().Sub(t),
-					SizeIn:    len(body),
+				hits <- report.Hit{
+					Tick:        tick,
+					Path:        req.URL.Path,
+					Tag:         spear.Tag,
+					Timestamp:   t,
+					ProtoCode:   resp.StatusCode,
+					TotalTime:   now.Sub(t),
+					ConnectTime: trace.connectTime(),
+					SendTime:    trace.sendTime(t),
+					ReceiveTime: trace.receiveTime(now),
+					SizeIn:      len(body),
+					SizeOut:     sizeOut,
+					NetCode:     report.NetCodeOK,
 				}
 			} else {
-				hits <- Hit{
-					Error: err,
+				hits <- report.Hit{
+					Tick:        tick,
+					Path:        req.URL.Path,
+					Tag:         spear.Tag,
+					Timestamp:   t,
+					TotalTime:   now.Sub(t),
+					ConnectTime: trace.connectTime(),
+					SendTime:    trace.sendTime(t),
+					SizeOut:     sizeOut,
+					NetCode:     report.NetCodeReadFail,
+					Error:       err,
 				}
 			}
 		} else {
-			hits <- Hit{
-				Error: err,
+			hits <- report.Hit{
+				Tick:        tick,
+				Path:        req.URL.Path,
+				Tag:         spear.Tag,
+				Timestamp:   t,
+				TotalTime:   time.Now().Sub(t),
+				ConnectTime: trace.connectTime(),
+				SendTime:    trace.sendTime(t),
+				SizeOut:     sizeOut,
+				NetCode:     trace.netCode(hardCtx, err),
+				Error:       err,
 			}
 		}
 	}
 	return nil
 }
 
+// runWorkerPool starts numWorkers Workers reading ammo from spears and
+// pacing off lancer, and blocks until they've all stopped.
+//
+// On ctx.Done() it gives in-flight requests up to drainTimeout to finish on
+// their own before hard-canceling them; a drainTimeout of 0 cancels them
+// immediately.
+func runWorkerPool(ctx context.Context, numWorkers int, spears chan *ammo.Request,
+	lancer *Lancer, hits chan report.Hit, rt http.RoundTripper, drainTimeout time.Duration) error {
+
+	hardCtx, hardCancel := context.WithCancel(context.Background())
+	defer hardCancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Worker(ctx, hardCtx, spears, lancer, hits, rt)
+		}()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		hardCancel()
+		<-drained
+	}
+	return nil
+}
+
+// reportFlags holds the configuration needed to build non-default
+// Reporters, i.e. the ones with their own connection settings.
+type reportFlags struct {
+	phoutFile string
+
+	influxURL    string
+	influxOrg    string
+	influxBucket string
+	influxToken  string
+
+	overloadAPI   string
+	overloadJob   string
+	overloadToken string
+}
+
+// reportersFromFlag builds the list of Reporters named in a comma-separated
+// -report value, e.g. "console,histogram,phout".
+func reportersFromFlag(spec string, rf reportFlags) ([]report.Reporter, error) {
+	var reporters []report.Reporter
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(name) {
+		case "console":
+			reporters = append(reporters, &report.Console{})
+		case "histogram":
+			reporters = append(reporters, &report.Histogram{Window: 5, Every: 5 * time.Second})
+		case "phout":
+			f, err := os.Create(rf.phoutFile)
+			if err != nil {
+				return nil, fmt.Errorf("can't open phout file: %s", err)
+			}
+			reporters = append(reporters, &report.Phout{Writer: f})
+		case "influxdb":
+			reporters = append(reporters, &report.Influx{
+				URL:    rf.influxURL,
+				Org:    rf.influxOrg,
+				Bucket: rf.influxBucket,
+				Token:  rf.influxToken,
+			})
+		case "overload":
+			reporters = append(reporters, &report.Overload{
+				APIAddress: rf.overloadAPI,
+				JobName:    rf.overloadJob,
+				Token:      rf.overloadToken,
+			})
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown reporter %q", name)
+		}
+	}
+	return reporters, nil
+}
+
 func main() {
 
-	low := flag.Int("l", 0, "RPS value to start test with")
-	high := flag.Int("h", 60, "RPS value to finish test with")
-	duration := flag.Duration("d", time.Minute, "test duration")
-	filename := flag.String("f", "access.log", "access.log file location")
+	low := flag.Int("l", 0, "RPS value to start test with, used when -load is not set")
+	high := flag.Int("h", 60, "RPS value to finish test with, used when -load is not set")
+	duration := flag.Duration("d", time.Minute, "test duration, used when -load is not set")
+	loadSpec := flag.String("load", "", `load schedule, e.g. 'linear(1,100,60s) step(100,500,50,10s) const(500,120s)'; overrides -l/-h/-d when set`)
+	filename := flag.String("f", "access.log", "ammo file location")
 	target := flag.String("t", "localhost", "target")
 	scheme := flag.String("s", "http", "scheme")
+	ammoType := flag.String("ammo-type", "access-log", "ammo format: access-log, json, raw, uri-list")
+	logFormat := flag.String("log-format", ammo.DefaultLogFormat, "access-log field template, used when -ammo-type is access-log")
+	loop := flag.Bool("loop", false, "cycle the ammo source so long tests don't run out of ammo")
 	numWorkers := flag.Int("w", 1024, "max number of concurrent requests")
 	missedFrac := flag.Int("q", 100, "max fraction of missed lances")
-	cancelRequestsOnStop := flag.Bool("x", false, "don't wait for pending requests after finish")
+	cancelRequestsOnStop := flag.Bool("x", false, "don't wait for pending requests after finish (equivalent to -drain-timeout=0)")
+	drainTimeoutFlag := flag.Duration("drain-timeout", 30*time.Second, "max time to let in-flight requests finish after the run ends before canceling them")
+	reportSpec := flag.String("report", "console,histogram", "comma-separated list of reporters to run (console, histogram, phout, influxdb, overload)")
+	phoutFile := flag.String("phout-file", "phout.log", "phout output file, used when -report includes phout")
+	influxURL := flag.String("influx-url", "http://localhost:8086", "InfluxDB base URL, used when -report includes influxdb")
+	influxOrg := flag.String("influx-org", "", "InfluxDB organization")
+	influxBucket := flag.String("influx-bucket", "lancer", "InfluxDB bucket")
+	influxToken := flag.String("influx-token", "", "InfluxDB auth token")
+	overloadAPI := flag.String("overload-api", "https://overload.yandex.net", "overload.yandex.ru API address, used when -report includes overload")
+	overloadJob := flag.String("overload-job", "", "overload.yandex.ru job name")
+	overloadToken := flag.String("overload-token", "", "overload.yandex.ru auth token")
+	failRate := flag.Float64("fail-rate", 0, "probability (0..1) of answering a request with a synthetic failure instead of sending it")
+	latencyInject := flag.Duration("latency-inject", 0, "extra random delay (0..n) added before every request")
+	dropRate := flag.Float64("drop-rate", 0, "probability (0..1) that a request hangs until canceled, simulating a server that never responds")
+	faultHost := flag.String("fault-host", "", "regexp restricting -fail-rate/-latency-inject/-drop-rate to matching request hosts")
+	faultPath := flag.String("fault-path", "", "regexp restricting -fail-rate/-latency-inject/-drop-rate to matching request paths")
 
 	flag.Parse()
 
-	spears := make(chan *http.Request)
-	lance := make(chan time.Duration)
-	defer close(lance)
-	hits := make(chan Hit, 10000)
+	reporters, err := reportersFromFlag(*reportSpec, reportFlags{
+		phoutFile:     *phoutFile,
+		influxURL:     *influxURL,
+		influxOrg:     *influxOrg,
+		influxBucket:  *influxBucket,
+		influxToken:   *influxToken,
+		overloadAPI:   *overloadAPI,
+		overloadJob:   *overloadJob,
+		overloadToken: *overloadToken,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var sched schedule.Schedule
+	if *loadSpec != "" {
+		sched, err = schedule.Parse(*loadSpec)
+	} else {
+		sched = schedule.NewLinear(float64(*low), float64(*high), *duration)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	provider, err := ammoProviderFromFlag(*ammoType, *filename, *logFormat, *scheme, *target)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *loop {
+		provider = &ammo.Loop{Provider: provider}
+	}
+
+	rt, err := roundTripperFromFlag(transportFlags{
+		failRate:      *failRate,
+		latencyInject: *latencyInject,
+		dropRate:      *dropRate,
+		faultHost:     *faultHost,
+		faultPath:     *faultPath,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	drainTimeout := *drainTimeoutFlag
+	if *cancelRequestsOnStop {
+		drainTimeout = 0
+	}
+
+	spears := make(chan *ammo.Request)
+	hits := make(chan report.Hit, 10000)
+	lancer := NewLancer(sched, *missedFrac)
 
 	ctx, stop := context.WithCancel(context.Background())
 
@@ -195,73 +427,28 @@ func main() {
 
 	g.Go(func() error {
 		defer close(spears)
-		return Parse(ctx, *filename, *scheme, *target, spears, *cancelRequestsOnStop)
+		return provider.Run(ctx, spears)
 	})
 
-	readyWorkers = make(chan int, 100)
-	defer close(readyWorkers)
-
 	g.Go(func() error {
 		defer close(hits)
-		var wg sync.WaitGroup
-		for i := 0; i < *numWorkers; i++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				Worker(ctx, spears, lance, hits)
-			}()
-		}
-		wg.Wait()
-		return nil
-	})
-
-	var workersReady int
-	for workersReady < *numWorkers {
-		workersReady += <-readyWorkers
-	}
-
-	g.Go(func() error {
-		for {
-			select {
-			case d := <-readyWorkers:
-				workersReady += d
-				if workersReady == 0 {
-					return errors.New("No ready workers left!")
-				}
-			case <-ctx.Done():
-				return nil
-			}
-		}
+		return runWorkerPool(ctx, *numWorkers, spears, lancer, hits, rt, drainTimeout)
 	})
 
 	g.Go(func() error {
-		// TODO: influxdb output
-		// TODO: phout output
-		// TODO: overload.yandex.ru output
-		running := true
-		for running {
-			select {
-			case hit := <-hits:
-				fmt.Printf("%v\n", hit)
-			case <-ctx.Done():
-				running = false
-			}
-		}
-		for hit := range hits {
-			log.Printf("Response after stop: %v", hit)
-		}
-		return nil
+		multi := &report.Multi{Reporters: reporters}
+		return multi.Run(ctx, hits)
 	})
 
 	g.Go(func() error {
 		defer stop()
-		lancer := NewLancer(float64(*low), float64(*high), *duration, *missedFrac)
-		return lancer.Lance(ctx, lance)
+		return lancer.Run(ctx)
 	})
 
-	err := g.Wait()
-	if err != nil {
+	if err := g.Wait(); err != nil {
 		log.Fatal(err)
 	}
 
+	stats := lancer.Stats()
+	log.Printf("issued %d, missed %d, skipped %d", stats.Issued, stats.Missed, stats.Skipped)
 }
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ei-grad/lancer/ammo"
+	"github.com/ei-grad/lancer/report"
+	"github.com/ei-grad/lancer/schedule"
+)
+
+type stubRoundTripper struct{}
+
+func (stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte("ok"))),
+		Header:     make(http.Header),
+	}, nil
+}
+
+// cancelingErrorRoundTripper cancels cancel before failing every request,
+// simulating a RoundTrip failure that lands after the worker pool's
+// lifecycle ctx is canceled (e.g. during the post-schedule drain window).
+type cancelingErrorRoundTripper struct {
+	cancel context.CancelFunc
+	err    error
+}
+
+func (rt *cancelingErrorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.cancel()
+	return nil, rt.err
+}
+
+// TestWorkerPopulatesHitSizeOut checks Worker fills in Hit.SizeOut from the
+// outgoing request, so phout/influx exports reflect the request body size
+// instead of always reporting 0.
+func TestWorkerPopulatesHitSizeOut(t *testing.T) {
+	const body = "hello world"
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	spears := make(chan *ammo.Request, 1)
+	spears <- &ammo.Request{Request: req}
+	close(spears)
+
+	lancer := NewLancer(schedule.NewConst(1000, time.Second), 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go lancer.Run(ctx)
+
+	hits := make(chan report.Hit, 1)
+	if err := Worker(ctx, ctx, spears, lancer, hits, stubRoundTripper{}); err != nil {
+		t.Fatalf("Worker: %v", err)
+	}
+
+	select {
+	case hit := <-hits:
+		if hit.SizeOut != len(body) {
+			t.Errorf("SizeOut = %d, want %d", hit.SizeOut, len(body))
+		}
+	default:
+		t.Fatal("expected a hit to be recorded")
+	}
+}
+
+// TestWorkerClassifiesNetCodeFromHardCtx checks Worker classifies a
+// RoundTrip failure using hardCtx (which governs the in-flight request),
+// not the worker pool's lifecycle ctx (which is canceled as soon as the
+// schedule finishes, well before requests are force-canceled). A failure
+// during that drain window must not be misclassified as NetCodeTimeout.
+func TestWorkerClassifiesNetCodeFromHardCtx(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	spears := make(chan *ammo.Request, 1)
+	spears <- &ammo.Request{Request: req}
+	close(spears)
+
+	lancer := NewLancer(schedule.NewConst(1000, time.Second), 100)
+	poolCtx, cancelPool := context.WithCancel(context.Background())
+	defer cancelPool()
+	go lancer.Run(poolCtx)
+
+	hardCtx, cancelHard := context.WithCancel(context.Background())
+	defer cancelHard()
+
+	rt := &cancelingErrorRoundTripper{cancel: cancelPool, err: errors.New("connection refused")}
+
+	hits := make(chan report.Hit, 1)
+	if err := Worker(poolCtx, hardCtx, spears, lancer, hits, rt); err != nil {
+		t.Fatalf("Worker: %v", err)
+	}
+
+	select {
+	case hit := <-hits:
+		if hit.NetCode == report.NetCodeTimeout {
+			t.Errorf("NetCode = %v, want something other than NetCodeTimeout (hardCtx was never canceled)", hit.NetCode)
+		}
+		if hit.NetCode != report.NetCodeConnectFail {
+			t.Errorf("NetCode = %v, want NetCodeConnectFail", hit.NetCode)
+		}
+	default:
+		t.Fatal("expected a hit to be recorded")
+	}
+}
+
+// TestRequestTraceConnectTimeIncludesDNS checks connectTime folds in DNS
+// lookup time rather than starting the clock at ConnectStart, per its doc
+// comment.
+func TestRequestTraceConnectTimeIncludesDNS(t *testing.T) {
+	now := time.Now()
+	rt := &requestTrace{
+		dnsStart:     now,
+		connectStart: now.Add(10 * time.Millisecond),
+		connAcquired: now.Add(30 * time.Millisecond),
+	}
+	if got, want := rt.connectTime(), 30*time.Millisecond; got != want {
+		t.Errorf("connectTime() = %v, want %v", got, want)
+	}
+}
+
+// TestRequestTraceConnectTimeReusedConn checks connectTime reports 0 when
+// the connection was reused and ConnectStart never fired.
+func TestRequestTraceConnectTimeReusedConn(t *testing.T) {
+	rt := &requestTrace{}
+	if got := rt.connectTime(); got != 0 {
+		t.Errorf("connectTime() = %v, want 0", got)
+	}
+}
+
+// TestRequestTraceConnectTimeNoDNS checks connectTime falls back to
+// connectStart when DNS wasn't traced (e.g. connecting to a bare IP).
+func TestRequestTraceConnectTimeNoDNS(t *testing.T) {
+	now := time.Now()
+	rt := &requestTrace{
+		connectStart: now,
+		connAcquired: now.Add(5 * time.Millisecond),
+	}
+	if got, want := rt.connectTime(), 5*time.Millisecond; got != want {
+		t.Errorf("connectTime() = %v, want %v", got, want)
+	}
+}
+
+// TestRequestTraceSendTime checks sendTime measures from connAcquired (or
+// the request start, for a reused connection) to WroteRequest.
+func TestRequestTraceSendTime(t *testing.T) {
+	now := time.Now()
+	rt := &requestTrace{
+		connAcquired: now,
+		wroteRequest: now.Add(2 * time.Millisecond),
+	}
+	if got, want := rt.sendTime(now), 2*time.Millisecond; got != want {
+		t.Errorf("sendTime() = %v, want %v", got, want)
+	}
+
+	reused := &requestTrace{wroteRequest: now.Add(3 * time.Millisecond)}
+	if got, want := reused.sendTime(now), 3*time.Millisecond; got != want {
+		t.Errorf("sendTime() on reused conn = %v, want %v", got, want)
+	}
+}
+
+// TestRequestTraceReceiveTime checks receiveTime measures from the first
+// response byte to the caller-supplied end time.
+func TestRequestTraceReceiveTime(t *testing.T) {
+	now := time.Now()
+	rt := &requestTrace{firstByte: now}
+	if got, want := rt.receiveTime(now.Add(4*time.Millisecond)), 4*time.Millisecond; got != want {
+		t.Errorf("receiveTime() = %v, want %v", got, want)
+	}
+}
+
+// TestRequestTraceNetCode checks netCode classifies DNS failures, connect
+// failures, timeouts and successes per NetCode's ordering.
+func TestRequestTraceNetCode(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name string
+		rt   *requestTrace
+		ctx  context.Context
+		err  error
+		want report.NetCode
+	}{
+		{"dns failure", &requestTrace{dnsErr: context.DeadlineExceeded}, context.Background(), nil, report.NetCodeDNSFail},
+		{"connect failure", &requestTrace{connectErr: context.DeadlineExceeded}, context.Background(), nil, report.NetCodeConnectFail},
+		{"timeout", &requestTrace{}, canceledCtx, context.Canceled, report.NetCodeTimeout},
+		{"other error", &requestTrace{}, context.Background(), context.Canceled, report.NetCodeConnectFail},
+		{"ok", &requestTrace{}, context.Background(), nil, report.NetCodeOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.rt.netCode(c.ctx, c.err); got != c.want {
+				t.Errorf("netCode() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
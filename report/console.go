@@ -0,0 +1,19 @@
+package report
+
+import (
+	"context"
+	"fmt"
+)
+
+// Console prints every Hit as it arrives, one line per request. It is the
+// direct replacement for the fmt.Printf-per-Hit behavior lancer started
+// with.
+type Console struct{}
+
+// Run implements Reporter.
+func (c *Console) Run(ctx context.Context, hits <-chan Hit) error {
+	for hit := range hits {
+		fmt.Printf("%v\n", hit)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestOverloadUploadsAfterCtxCanceled reproduces the normal post-run
+// shutdown: by the time hits closes, ctx is already canceled, so the
+// final upload must not be built against it.
+func TestOverloadUploadsAfterCtxCanceled(t *testing.T) {
+	var received bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	o := &Overload{APIAddress: srv.URL, JobName: "job"}
+	hits := make(chan Hit, 1)
+	hits <- Hit{Path: "/x"}
+	close(hits)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := o.Run(ctx, hits); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !received {
+		t.Fatal("expected the final upload to reach the server")
+	}
+}
+
+// TestInfluxFinalFlushAfterCtxCanceled reproduces the same post-run
+// timing for Influx's final flush on channel close.
+func TestInfluxFinalFlushAfterCtxCanceled(t *testing.T) {
+	var received bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	i := &Influx{URL: srv.URL, FlushEvery: time.Hour}
+	hits := make(chan Hit, 1)
+	hits <- Hit{Path: "/x"}
+	close(hits)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := i.Run(ctx, hits); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !received {
+		t.Fatal("expected the final write to reach the server")
+	}
+}
+
+// TestInfluxTickerFlushAfterCtxCanceled reproduces the drain window: the
+// schedule has finished and ctx is canceled, but hits keeps trickling in
+// until workers finish draining, so the ticker-triggered flush must not be
+// built against the already-canceled ctx either.
+func TestInfluxTickerFlushAfterCtxCanceled(t *testing.T) {
+	var received bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	i := &Influx{URL: srv.URL, FlushEvery: time.Millisecond}
+	hits := make(chan Hit, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- i.Run(ctx, hits) }()
+
+	hits <- Hit{Path: "/x"}
+	time.Sleep(10 * time.Millisecond)
+	close(hits)
+
+	if err := <-errc; err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !received {
+		t.Fatal("expected the ticker-triggered flush to reach the server")
+	}
+}
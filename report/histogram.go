@@ -0,0 +1,330 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/codahale/hdrhistogram"
+)
+
+const (
+	minLatency = int64(time.Microsecond)
+	maxLatency = int64(time.Minute)
+	sigFigs    = 3
+)
+
+var percentiles = []float64{50, 90, 95, 99, 99.9}
+
+// windowBucket holds every stat summary() needs for one second of the
+// trailing window: a latency histogram alongside the totals, status codes
+// and paths seen during that second. rotate() resets all of them together
+// so "Current" reflects the trailing window, not the run's lifetime.
+type windowBucket struct {
+	hist        *hdrhistogram.Histogram
+	total       int
+	errors      int
+	fastest     time.Duration
+	slowest     time.Duration
+	statusCodes map[int]int
+	paths       map[string]int
+}
+
+func newWindowBucket() *windowBucket {
+	return &windowBucket{
+		hist:        hdrhistogram.New(minLatency, maxLatency, sigFigs),
+		statusCodes: make(map[int]int),
+		paths:       make(map[string]int),
+	}
+}
+
+func (b *windowBucket) reset() {
+	b.hist.Reset()
+	b.total = 0
+	b.errors = 0
+	b.fastest = 0
+	b.slowest = 0
+	b.statusCodes = make(map[int]int)
+	b.paths = make(map[string]int)
+}
+
+func (b *windowBucket) record(hit Hit) {
+	if hit.Error != nil {
+		b.errors++
+		return
+	}
+	b.total++
+	b.statusCodes[hit.ProtoCode]++
+	b.paths[hit.Path]++
+	b.hist.RecordValue(clampLatency(hit.TotalTime))
+	if b.fastest == 0 || hit.TotalTime < b.fastest {
+		b.fastest = hit.TotalTime
+	}
+	if hit.TotalTime > b.slowest {
+		b.slowest = hit.TotalTime
+	}
+}
+
+// histSnapshot is everything summary() needs to render a report, gathered
+// up front so summary() itself doesn't need to know whether it came from a
+// trailing window or the run's full lifetime.
+type histSnapshot struct {
+	elapsed     time.Duration
+	hist        *hdrhistogram.Histogram
+	total       int
+	errors      int
+	fastest     time.Duration
+	slowest     time.Duration
+	statusCodes map[int]int
+	paths       map[string]int
+}
+
+// rotatingHistogram keeps n per-second windowBuckets and rotates the
+// oldest one out as time advances, so Current always aggregates roughly
+// the trailing n seconds while the buckets it still holds keep accepting
+// new values.
+type rotatingHistogram struct {
+	buckets []*windowBucket
+	pos     int
+}
+
+func newRotatingHistogram(n int) *rotatingHistogram {
+	h := &rotatingHistogram{buckets: make([]*windowBucket, n)}
+	for i := range h.buckets {
+		h.buckets[i] = newWindowBucket()
+	}
+	return h
+}
+
+func (h *rotatingHistogram) record(hit Hit) {
+	h.buckets[h.pos].record(hit)
+}
+
+// rotate advances to the next second-of-window bucket, resetting it so it
+// no longer contributes to Current.
+func (h *rotatingHistogram) rotate() {
+	h.pos = (h.pos + 1) % len(h.buckets)
+	h.buckets[h.pos].reset()
+}
+
+// current returns the aggregated stats over all buckets still held, i.e.
+// the trailing window.
+func (h *rotatingHistogram) current() histSnapshot {
+	snap := histSnapshot{
+		hist:        hdrhistogram.New(minLatency, maxLatency, sigFigs),
+		statusCodes: make(map[int]int),
+		paths:       make(map[string]int),
+	}
+	for _, b := range h.buckets {
+		snap.hist.Merge(b.hist)
+		snap.total += b.total
+		snap.errors += b.errors
+		if snap.fastest == 0 || (b.fastest != 0 && b.fastest < snap.fastest) {
+			snap.fastest = b.fastest
+		}
+		if b.slowest > snap.slowest {
+			snap.slowest = b.slowest
+		}
+		for k, v := range b.statusCodes {
+			snap.statusCodes[k] += v
+		}
+		for k, v := range b.paths {
+			snap.paths[k] += v
+		}
+	}
+	return snap
+}
+
+func clampLatency(d time.Duration) int64 {
+	v := int64(d)
+	if v < minLatency {
+		return minLatency
+	}
+	if v > maxLatency {
+		return maxLatency
+	}
+	return v
+}
+
+// Histogram is a Reporter that keeps an HDR-style rotating latency
+// histogram plus running totals. It periodically prints "Current" stats
+// for the trailing window, and on completion prints a Boom/hey-style
+// "Cumulative" summary built from every Hit seen.
+type Histogram struct {
+	// Window is how many one-second buckets make up the trailing "current"
+	// view. Defaults to 5 if zero.
+	Window int
+	// Every is how often the current window is printed. Periodic printing
+	// is skipped if Every is zero.
+	Every time.Duration
+
+	mu          sync.Mutex
+	current     *rotatingHistogram
+	window      time.Duration
+	cumulative  *hdrhistogram.Histogram
+	statusCodes map[int]int
+	paths       map[string]int
+	total       int
+	errors      int
+	fastest     time.Duration
+	slowest     time.Duration
+	start       time.Time
+}
+
+// Run implements Reporter.
+func (h *Histogram) Run(ctx context.Context, hits <-chan Hit) error {
+	windowSize := h.Window
+	if windowSize <= 0 {
+		windowSize = 5
+	}
+	h.current = newRotatingHistogram(windowSize)
+	h.window = time.Duration(windowSize) * time.Second
+	h.cumulative = hdrhistogram.New(minLatency, maxLatency, sigFigs)
+	h.statusCodes = make(map[int]int)
+	h.paths = make(map[string]int)
+	h.start = time.Now()
+
+	rotate := time.NewTicker(time.Second)
+	defer rotate.Stop()
+
+	var report *time.Ticker
+	var reportC <-chan time.Time
+	if h.Every > 0 {
+		report = time.NewTicker(h.Every)
+		reportC = report.C
+		defer report.Stop()
+	}
+
+	for {
+		select {
+		case hit, ok := <-hits:
+			if !ok {
+				fmt.Print(h.summary("Cumulative", h.cumulativeSnapshot()))
+				return nil
+			}
+			h.record(hit)
+		case <-rotate.C:
+			h.mu.Lock()
+			h.current.rotate()
+			h.mu.Unlock()
+		case <-reportC:
+			fmt.Print(h.summary("Current", h.currentSnapshot()))
+		}
+	}
+}
+
+func (h *Histogram) record(hit Hit) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.current.record(hit)
+
+	if hit.Error != nil {
+		h.errors++
+		return
+	}
+
+	h.total++
+	h.statusCodes[hit.ProtoCode]++
+	h.paths[hit.Path]++
+	h.cumulative.RecordValue(clampLatency(hit.TotalTime))
+
+	if h.fastest == 0 || hit.TotalTime < h.fastest {
+		h.fastest = hit.TotalTime
+	}
+	if hit.TotalTime > h.slowest {
+		h.slowest = hit.TotalTime
+	}
+}
+
+// cumulativeSnapshot captures the run's lifetime stats for the final
+// summary.
+func (h *Histogram) cumulativeSnapshot() histSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	statusCodes := make(map[int]int, len(h.statusCodes))
+	for k, v := range h.statusCodes {
+		statusCodes[k] = v
+	}
+	paths := make(map[string]int, len(h.paths))
+	for k, v := range h.paths {
+		paths[k] = v
+	}
+	return histSnapshot{
+		elapsed:     time.Since(h.start),
+		hist:        h.cumulative,
+		total:       h.total,
+		errors:      h.errors,
+		fastest:     h.fastest,
+		slowest:     h.slowest,
+		statusCodes: statusCodes,
+		paths:       paths,
+	}
+}
+
+// currentSnapshot captures the trailing window's stats for a periodic
+// "Current" printout.
+func (h *Histogram) currentSnapshot() histSnapshot {
+	h.mu.Lock()
+	snap := h.current.current()
+	elapsed := time.Since(h.start)
+	h.mu.Unlock()
+	if elapsed > h.window {
+		elapsed = h.window
+	}
+	snap.elapsed = elapsed
+	return snap
+}
+
+// summary renders a Boom/hey-style report for the given label and
+// snapshot.
+func (h *Histogram) summary(label string, snap histSnapshot) string {
+	rps := float64(snap.total) / snap.elapsed.Seconds()
+
+	out := fmt.Sprintf("\n%s:\n", label)
+	out += fmt.Sprintf("  Total:        %s\n", snap.elapsed.Round(time.Millisecond))
+	out += fmt.Sprintf("  Slowest:      %s\n", snap.slowest)
+	out += fmt.Sprintf("  Fastest:      %s\n", snap.fastest)
+	out += fmt.Sprintf("  Average:      %s\n", time.Duration(snap.hist.Mean()))
+	out += fmt.Sprintf("  Requests/sec: %.2f\n", rps)
+	if snap.errors > 0 {
+		out += fmt.Sprintf("  Errors:       %d\n", snap.errors)
+	}
+
+	out += "\nStatus code distribution:\n"
+	for _, code := range sortedIntKeys(snap.statusCodes) {
+		out += fmt.Sprintf("  [%d]\t%d responses\n", code, snap.statusCodes[code])
+	}
+
+	out += "\nPath distribution:\n"
+	for _, path := range sortedStringKeys(snap.paths) {
+		out += fmt.Sprintf("  [%d]\t%s\n", snap.paths[path], path)
+	}
+
+	out += "\nLatency distribution:\n"
+	for _, p := range percentiles {
+		out += fmt.Sprintf("  %5.1f%% in %s\n", p, time.Duration(snap.hist.ValueAtQuantile(p)))
+	}
+
+	return out
+}
+
+func sortedIntKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func sortedStringKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRotatingHistogramWindowsOutOldHits checks that rotate() drops a
+// bucket's contribution from current() — total, fastest/slowest, status
+// codes and paths, not just the latency histogram.
+func TestRotatingHistogramWindowsOutOldHits(t *testing.T) {
+	h := newRotatingHistogram(2)
+
+	h.record(Hit{Path: "/old", ProtoCode: 500, TotalTime: time.Millisecond})
+	snap := h.current()
+	if snap.total != 1 {
+		t.Fatalf("total = %d, want 1", snap.total)
+	}
+	if snap.paths["/old"] != 1 {
+		t.Fatalf("paths[/old] = %d, want 1", snap.paths["/old"])
+	}
+	if snap.statusCodes[500] != 1 {
+		t.Fatalf("statusCodes[500] = %d, want 1", snap.statusCodes[500])
+	}
+
+	// Rotating twice (the window is 2 buckets wide) fully retires the
+	// bucket the old hit landed in.
+	h.rotate()
+	h.rotate()
+
+	h.record(Hit{Path: "/new", ProtoCode: 200, TotalTime: 5 * time.Millisecond})
+	snap = h.current()
+
+	if snap.total != 1 {
+		t.Errorf("total = %d, want 1 (old hit should have rotated out)", snap.total)
+	}
+	if _, ok := snap.paths["/old"]; ok {
+		t.Errorf("paths still contains /old after it rotated out of the window")
+	}
+	if _, ok := snap.statusCodes[500]; ok {
+		t.Errorf("statusCodes still contains 500 after it rotated out of the window")
+	}
+	if snap.paths["/new"] != 1 {
+		t.Errorf("paths[/new] = %d, want 1", snap.paths["/new"])
+	}
+	if snap.fastest != 5*time.Millisecond || snap.slowest != 5*time.Millisecond {
+		t.Errorf("fastest/slowest = %v/%v, want both 5ms", snap.fastest, snap.slowest)
+	}
+}
+
+// TestRotatingHistogramAggregatesAcrossBuckets checks current() merges
+// fastest/slowest/total across every bucket still held, not just the one
+// most recently written to.
+func TestRotatingHistogramAggregatesAcrossBuckets(t *testing.T) {
+	h := newRotatingHistogram(3)
+
+	h.record(Hit{Path: "/a", TotalTime: 10 * time.Millisecond})
+	h.rotate()
+	h.record(Hit{Path: "/b", TotalTime: 2 * time.Millisecond})
+	h.rotate()
+	h.record(Hit{Path: "/c", TotalTime: 20 * time.Millisecond})
+
+	snap := h.current()
+	if snap.total != 3 {
+		t.Fatalf("total = %d, want 3", snap.total)
+	}
+	if snap.fastest != 2*time.Millisecond {
+		t.Errorf("fastest = %v, want 2ms", snap.fastest)
+	}
+	if snap.slowest != 20*time.Millisecond {
+		t.Errorf("slowest = %v, want 20ms", snap.slowest)
+	}
+}
+
+// TestWindowBucketRecordCountsErrorsSeparately checks a hit with Error set
+// bumps errors without touching total/latency/status/path stats.
+func TestWindowBucketRecordCountsErrorsSeparately(t *testing.T) {
+	b := newWindowBucket()
+	b.record(Hit{Error: errors.New("boom")})
+	if b.errors != 1 {
+		t.Errorf("errors = %d, want 1", b.errors)
+	}
+	if b.total != 0 {
+		t.Errorf("total = %d, want 0", b.total)
+	}
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Influx batches Hits and periodically POSTs them as InfluxDB line protocol
+// to a configurable bucket.
+type Influx struct {
+	// URL is the InfluxDB server base address, e.g. "http://localhost:8086".
+	URL    string
+	Org    string
+	Bucket string
+	Token  string
+
+	// BatchSize and FlushEvery bound how long hits are buffered before a
+	// write; defaults apply when zero.
+	BatchSize  int
+	FlushEvery time.Duration
+
+	Client *http.Client
+}
+
+// Run implements Reporter.
+func (i *Influx) Run(ctx context.Context, hits <-chan Hit) error {
+	batchSize := i.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	flushEvery := i.FlushEvery
+	if flushEvery <= 0 {
+		flushEvery = time.Second
+	}
+
+	var buf bytes.Buffer
+	var n int
+	ticker := time.NewTicker(flushEvery)
+	defer ticker.Stop()
+
+	flush := func(ctx context.Context) error {
+		if n == 0 {
+			return nil
+		}
+		err := i.write(ctx, buf.Bytes())
+		buf.Reset()
+		n = 0
+		return err
+	}
+
+	// The ticker and batch-size triggers fire throughout the drain window
+	// after the run's lifecycle ctx is canceled (hits keep arriving until
+	// the channel closes), so they flush against their own short-lived
+	// context rather than ctx to avoid failing on a canceled one.
+	flushDetached := func() error {
+		detachedCtx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+		defer cancel()
+		return flush(detachedCtx)
+	}
+
+	for {
+		select {
+		case hit, ok := <-hits:
+			if !ok {
+				// hits only closes once the run's lifecycle ctx is
+				// already canceled, so the last flush needs its own
+				// context rather than the done one.
+				finalCtx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+				defer cancel()
+				return flush(finalCtx)
+			}
+			writeLineProtocol(&buf, hit)
+			n++
+			if n >= batchSize {
+				if err := flushDetached(); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if err := flushDetached(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeLineProtocol(buf *bytes.Buffer, hit Hit) {
+	fmt.Fprintf(buf,
+		"lancer,path=%s,tag=%s,proto_code=%d status=%di,latency=%di,connect_time=%di,send_time=%di,receive_time=%di,size_in=%di,size_out=%di %d\n",
+		escapeTag(hit.Path), escapeTag(hit.Tag), hit.ProtoCode,
+		hit.ProtoCode, hit.TotalTime.Nanoseconds(), hit.ConnectTime.Nanoseconds(),
+		hit.SendTime.Nanoseconds(), hit.ReceiveTime.Nanoseconds(),
+		hit.SizeIn, hit.SizeOut, hit.Timestamp.UnixNano(),
+	)
+}
+
+func escapeTag(s string) string {
+	if s == "" {
+		s = "-"
+	}
+	r := strings.NewReplacer(" ", "\\ ", ",", "\\,", "=", "\\=")
+	return r.Replace(s)
+}
+
+func (i *Influx) write(ctx context.Context, body []byte) error {
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url := fmt.Sprintf("%s/api/v2/write?org=%s&bucket=%s&precision=ns", i.URL, i.Org, i.Bucket)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+i.Token)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("influxdb write: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("influxdb write: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestMultiDrainsAfterCtxCanceled reproduces Lancer.Run's normal shutdown
+// sequence: ctx is canceled as soon as the schedule finishes issuing
+// ticks, well before the worker pool's drain period ends and hits
+// actually closes. Multi.Run must keep fanning hits out to every Reporter
+// until hits closes, regardless of ctx, or its unconditional sends fill
+// a sub-channel's buffer and block forever.
+func TestMultiDrainsAfterCtxCanceled(t *testing.T) {
+	hits := make(chan Hit)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := &Multi{Reporters: []Reporter{&Histogram{}, &Histogram{}}}
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(ctx, hits) }()
+
+	cancel()
+
+	const n = 150 // more than a sub-channel's 100-slot buffer
+	for j := 0; j < n; j++ {
+		hits <- Hit{}
+	}
+	close(hits)
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Multi.Run returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Multi.Run did not return after hits closed")
+	}
+}
+
+// erroringReporter fails immediately, the way a Reporter with a broken
+// network sink does.
+type erroringReporter struct{ err error }
+
+func (e *erroringReporter) Run(ctx context.Context, hits <-chan Hit) error {
+	return e.err
+}
+
+// TestMultiSurvivesOneReporterFailing reproduces a Reporter returning an
+// error while hits are still arriving: Multi.Run must keep forwarding to
+// the other, still-running Reporters and return once hits closes, instead
+// of blocking forever on sends to the dead Reporter's now-unread channel.
+func TestMultiSurvivesOneReporterFailing(t *testing.T) {
+	boom := errors.New("boom")
+	hits := make(chan Hit)
+
+	m := &Multi{Reporters: []Reporter{&erroringReporter{err: boom}, &Histogram{}}}
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(context.Background(), hits) }()
+
+	const n = 200 // more than a sub-channel's 100-slot buffer
+	for j := 0; j < n; j++ {
+		hits <- Hit{}
+	}
+	close(hits)
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, boom) {
+			t.Fatalf("Multi.Run error = %v, want %v", err, boom)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Multi.Run did not return after the failing reporter errored")
+	}
+}
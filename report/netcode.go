@@ -0,0 +1,40 @@
+package report
+
+// NetCode classifies how a request attempt ended, mirroring phantom/phout's
+// net_code column: zero means success, anything else narrows down which
+// phase of the request failed.
+type NetCode int
+
+const (
+	// NetCodeOK means the request got a response.
+	NetCodeOK NetCode = iota
+	// NetCodeDNSFail means the target host name failed to resolve.
+	NetCodeDNSFail
+	// NetCodeConnectFail means the TCP or TLS connection could not be
+	// established, or the request failed before a response for any other
+	// reason that isn't a DNS failure or a timeout.
+	NetCodeConnectFail
+	// NetCodeTimeout means the context was canceled or deadline exceeded
+	// before a response was received.
+	NetCodeTimeout
+	// NetCodeReadFail means the request was sent but the response body
+	// could not be read.
+	NetCodeReadFail
+)
+
+func (c NetCode) String() string {
+	switch c {
+	case NetCodeOK:
+		return "ok"
+	case NetCodeDNSFail:
+		return "dns_fail"
+	case NetCodeConnectFail:
+		return "connect_fail"
+	case NetCodeTimeout:
+		return "timeout"
+	case NetCodeReadFail:
+		return "read_fail"
+	default:
+		return "unknown"
+	}
+}
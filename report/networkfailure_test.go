@@ -0,0 +1,79 @@
+package report
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestMultiSurvivesInfluxWriteFailure reproduces the network-hiccup case
+// this backlog item exists for: Influx.Run returns an error as soon as a
+// write gets a non-2xx response, while hits keep arriving. Multi.Run must
+// keep forwarding to the other Reporters and return once hits closes,
+// instead of wedging on the now-unread Influx channel.
+func TestMultiSurvivesInfluxWriteFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	hits := make(chan Hit)
+	m := &Multi{Reporters: []Reporter{
+		&Influx{URL: srv.URL, BatchSize: 1, FlushEvery: time.Hour},
+		&Histogram{},
+	}}
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(context.Background(), hits) }()
+
+	const n = 200 // more than a sub-channel's 100-slot buffer
+	for j := 0; j < n; j++ {
+		hits <- Hit{}
+	}
+	close(hits)
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("expected Multi.Run to surface the influxdb write failure")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Multi.Run did not return after Influx's write failed")
+	}
+}
+
+// TestMultiSurvivesOverloadUploadFailure covers the same scenario for
+// Overload, whose single failure point is the final upload once hits
+// closes.
+func TestMultiSurvivesOverloadUploadFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	hits := make(chan Hit)
+	m := &Multi{Reporters: []Reporter{
+		&Overload{APIAddress: srv.URL, JobName: "job"},
+		&Histogram{},
+	}}
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(context.Background(), hits) }()
+
+	const n = 200 // more than a sub-channel's 100-slot buffer
+	for j := 0; j < n; j++ {
+		hits <- Hit{}
+	}
+	close(hits)
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("expected Multi.Run to surface the overload upload failure")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Multi.Run did not return after Overload's upload failed")
+	}
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Overload buffers all Hits as phout lines and uploads them to
+// overload.yandex.ru once the test finishes, the way Yandex Tank publishes
+// local results to the online overload service.
+type Overload struct {
+	// APIAddress is the overload API base address, e.g.
+	// "https://overload.yandex.net".
+	APIAddress string
+	JobName    string
+	Token      string
+
+	Client *http.Client
+}
+
+// Run implements Reporter.
+func (o *Overload) Run(ctx context.Context, hits <-chan Hit) error {
+	var buf bytes.Buffer
+	for hit := range hits {
+		writePhoutLine(&buf, hit)
+	}
+	// hits only closes once the run's lifecycle ctx is already canceled,
+	// so the upload needs its own context rather than the done one.
+	uploadCtx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+	defer cancel()
+	return o.upload(uploadCtx, buf.Bytes())
+}
+
+func (o *Overload) upload(ctx context.Context, body []byte) error {
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	url := fmt.Sprintf("%s/api/job/%s/results.jtl", o.APIAddress, o.JobName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+o.Token)
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("overload upload: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("overload upload: unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Phout writes one line per Hit in the Yandex Tank "phout" format, so
+// results can be fed into existing tank post-processors.
+type Phout struct {
+	Writer io.Writer
+}
+
+// Run implements Reporter.
+func (p *Phout) Run(ctx context.Context, hits <-chan Hit) error {
+	w := bufio.NewWriter(p.Writer)
+	defer w.Flush()
+	for hit := range hits {
+		writePhoutLine(w, hit)
+	}
+	return w.Flush()
+}
+
+// writePhoutLine renders a single phout line:
+// timestamp tag interval_real connect_time send_time latency receive_time
+// interval_event size_out size_in net_code proto_code
+func writePhoutLine(w io.Writer, hit Hit) {
+	tag := hit.Tag
+	if tag == "" {
+		tag = "-"
+	}
+	latency := hit.TotalTime - hit.ConnectTime - hit.SendTime - hit.ReceiveTime
+	fmt.Fprintf(w, "%.3f\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+		float64(hit.Timestamp.UnixNano())/1e9,
+		tag,
+		hit.TotalTime.Microseconds(),
+		hit.ConnectTime.Microseconds(),
+		hit.SendTime.Microseconds(),
+		latency.Microseconds(),
+		hit.ReceiveTime.Microseconds(),
+		hit.Tick.Microseconds(),
+		hit.SizeOut,
+		hit.SizeIn,
+		int(hit.NetCode),
+		hit.ProtoCode,
+	)
+}
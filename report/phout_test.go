@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWritePhoutLineLatencyExcludesConnectAndSend checks latency follows
+// the phout/phantom convention (time to first byte), so
+// interval_real == connect_time + send_time + latency + receive_time
+// holds for downstream tank tooling.
+func TestWritePhoutLineLatencyExcludesConnectAndSend(t *testing.T) {
+	hit := Hit{
+		TotalTime:   100 * time.Millisecond,
+		ConnectTime: 20 * time.Millisecond,
+		SendTime:    10 * time.Millisecond,
+		ReceiveTime: 15 * time.Millisecond,
+	}
+
+	var buf bytes.Buffer
+	writePhoutLine(&buf, hit)
+
+	fields := strings.Split(strings.TrimSpace(buf.String()), "\t")
+	if len(fields) != 12 {
+		t.Fatalf("got %d fields, want 12: %q", len(fields), buf.String())
+	}
+
+	connectTime, err := strconv.Atoi(fields[3])
+	if err != nil {
+		t.Fatalf("connect_time: %v", err)
+	}
+	sendTime, err := strconv.Atoi(fields[4])
+	if err != nil {
+		t.Fatalf("send_time: %v", err)
+	}
+	latency, err := strconv.Atoi(fields[5])
+	if err != nil {
+		t.Fatalf("latency: %v", err)
+	}
+	receiveTime, err := strconv.Atoi(fields[6])
+	if err != nil {
+		t.Fatalf("receive_time: %v", err)
+	}
+	intervalReal, err := strconv.Atoi(fields[2])
+	if err != nil {
+		t.Fatalf("interval_real: %v", err)
+	}
+
+	if want := 55000; latency != want {
+		t.Errorf("latency = %d, want %d (100ms - 20ms - 10ms - 15ms)", latency, want)
+	}
+	if sum := connectTime + sendTime + latency + receiveTime; sum != intervalReal {
+		t.Errorf("connect_time+send_time+latency+receive_time = %d, want interval_real %d", sum, intervalReal)
+	}
+}
+
+// TestPhoutRunWritesOneLinePerHit checks Run flushes a line per Hit and
+// flushes the writer on completion.
+func TestPhoutRunWritesOneLinePerHit(t *testing.T) {
+	var buf bytes.Buffer
+	p := &Phout{Writer: &buf}
+	hits := make(chan Hit, 2)
+	hits <- Hit{Tag: "a"}
+	hits <- Hit{Tag: "b"}
+	close(hits)
+
+	if err := p.Run(context.Background(), hits); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+}
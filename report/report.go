@@ -0,0 +1,85 @@
+// Package report consumes the Hits produced by the load generator and turns
+// them into statistics: a live console trace, windowed/cumulative latency
+// histograms, or exports to external systems.
+package report
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// finalFlushTimeout bounds the last write a Reporter makes after its hits
+// channel closes. By that point the run's lifecycle ctx is already done
+// (Lancer.Run cancels it once the schedule finishes), so reporters that
+// need to talk to the network one more time use a fresh context with this
+// deadline instead of the canceled one.
+const finalFlushTimeout = 30 * time.Second
+
+// Hit describes the outcome of a single request sent during the test.
+type Hit struct {
+	Path string
+	// Tag optionally groups hits, e.g. by ammo source or URL template.
+	Tag string
+
+	Timestamp time.Time
+
+	Tick                             time.Duration
+	TotalTime, ConnectTime, SendTime time.Duration
+	ReceiveTime                      time.Duration
+	SizeIn, SizeOut, ProtoCode       int
+	NetCode                          NetCode
+	Error                            error
+}
+
+// Reporter consumes Hits from a channel until it is closed. The Reporters
+// in this package keep reading after ctx is done, since hits still arrive
+// while in-flight requests drain.
+// Implementations are expected to print or export a final summary before
+// returning.
+type Reporter interface {
+	Run(ctx context.Context, hits <-chan Hit) error
+}
+
+// Multi fans hits out to several Reporters so they run concurrently, each
+// with its own buffered copy of the stream.
+type Multi struct {
+	Reporters []Reporter
+}
+
+// Run implements Reporter by forwarding every hit to all configured
+// Reporters and waiting for them all to finish.
+//
+// A Reporter that returns before hits closes (e.g. it errored out) stops
+// being fed further hits instead of wedging the fan-out: sends to its
+// channel race against its own done signal, so one failing sink can't
+// block delivery to the others or back up hits into the worker pool.
+func (m *Multi) Run(ctx context.Context, hits <-chan Hit) error {
+	g, ctx := errgroup.WithContext(ctx)
+	chans := make([]chan Hit, len(m.Reporters))
+	done := make([]chan struct{}, len(m.Reporters))
+	for i, r := range m.Reporters {
+		c := make(chan Hit, 100)
+		d := make(chan struct{})
+		chans[i] = c
+		done[i] = d
+		r := r
+		g.Go(func() error {
+			defer close(d)
+			return r.Run(ctx, c)
+		})
+	}
+	for hit := range hits {
+		for i, c := range chans {
+			select {
+			case c <- hit:
+			case <-done[i]:
+			}
+		}
+	}
+	for _, c := range chans {
+		close(c)
+	}
+	return g.Wait()
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import "time"
+
+// Composite runs several Schedules back-to-back: once a schedule's ticks
+// are exhausted, the next one starts as if its own clock began where the
+// previous schedule's wall-clock duration ended.
+//
+// Only the last Schedule may be unbounded (Count() < 0, e.g. Instances):
+// Composite tracks segment boundaries by cumulative tick count, so it has
+// no way to tell when an unbounded schedule's ticks are "exhausted" and
+// move on to whatever follows it. Parse rejects such a chain up front;
+// building a Composite directly with one isn't validated.
+type Composite struct {
+	Schedules []Schedule
+
+	offsets []time.Duration
+	bounds  []int
+}
+
+func (c *Composite) init() {
+	if c.bounds != nil {
+		return
+	}
+	var elapsed time.Duration
+	total := 0
+	for _, s := range c.Schedules {
+		c.offsets = append(c.offsets, elapsed)
+		if total >= 0 {
+			if n := s.Count(); n >= 0 {
+				total += n
+			} else {
+				total = -1
+			}
+		}
+		c.bounds = append(c.bounds, total)
+		if b, ok := s.(Bounded); ok {
+			elapsed += b.Duration()
+		}
+	}
+}
+
+// Count implements Schedule. It is unbounded (-1) if any of its schedules
+// is unbounded.
+func (c *Composite) Count() int {
+	c.init()
+	if len(c.bounds) == 0 {
+		return 0
+	}
+	return c.bounds[len(c.bounds)-1]
+}
+
+// Next implements Schedule.
+func (c *Composite) Next(i int) time.Duration {
+	c.init()
+	prev := 0
+	for idx, bound := range c.bounds {
+		if bound < 0 || i <= bound {
+			return c.offsets[idx] + c.Schedules[idx].Next(i-prev)
+		}
+		prev = bound
+	}
+	last := len(c.Schedules) - 1
+	return c.offsets[last]
+}
+
+// Duration implements Bounded.
+func (c *Composite) Duration() time.Duration {
+	c.init()
+	var total time.Duration
+	for _, s := range c.Schedules {
+		if b, ok := s.(Bounded); ok {
+			total += b.Duration()
+		}
+	}
+	return total
+}
@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var callRE = regexp.MustCompile(`^(\w+)\(([^)]*)\)$`)
+
+// Parse builds a Schedule from a CLI grammar like
+// "linear(1,100,60s) step(100,500,50,10s) const(500,120s)". Several
+// space-separated calls are chained into a Composite; a single call
+// returns that Schedule directly.
+func Parse(spec string) (Schedule, error) {
+	var scheds []Schedule
+	tokens := strings.Fields(spec)
+	for _, tok := range tokens {
+		s, err := parseCall(tok)
+		if err != nil {
+			return nil, err
+		}
+		scheds = append(scheds, s)
+	}
+	// A Composite only knows to move on to the next schedule once it has
+	// counted as many ticks as the current one reports via Count(), so an
+	// unbounded schedule (e.g. instances()) anywhere but last would run
+	// forever and make everything chained after it unreachable.
+	for i, s := range scheds {
+		if i < len(scheds)-1 && s.Count() < 0 {
+			return nil, fmt.Errorf("%q never finishes by tick count, so it can't be followed by another schedule", tokens[i])
+		}
+	}
+	switch len(scheds) {
+	case 0:
+		return nil, fmt.Errorf("empty -load schedule")
+	case 1:
+		return scheds[0], nil
+	default:
+		return &Composite{Schedules: scheds}, nil
+	}
+}
+
+func parseCall(tok string) (Schedule, error) {
+	m := callRE.FindStringSubmatch(tok)
+	if m == nil {
+		return nil, fmt.Errorf("invalid schedule expression %q", tok)
+	}
+	name, rawArgs := m[1], m[2]
+
+	var args []string
+	if rawArgs != "" {
+		for _, a := range strings.Split(rawArgs, ",") {
+			args = append(args, strings.TrimSpace(a))
+		}
+	}
+
+	switch name {
+	case "const":
+		f, d, err := parseArgs(args, 1)
+		if err != nil {
+			return nil, fmt.Errorf("const(): %s", err)
+		}
+		return NewConst(f[0], d), nil
+	case "linear":
+		f, d, err := parseArgs(args, 2)
+		if err != nil {
+			return nil, fmt.Errorf("linear(): %s", err)
+		}
+		return NewLinear(f[0], f[1], d), nil
+	case "step":
+		f, d, err := parseArgs(args, 3)
+		if err != nil {
+			return nil, fmt.Errorf("step(): %s", err)
+		}
+		if f[2] <= 0 {
+			return nil, fmt.Errorf("step(): StepSize must be > 0, got %v", f[2])
+		}
+		return &Step{Low: f[0], High: f[1], StepSize: f[2], StepWidth: d}, nil
+	case "instances":
+		f, d, err := parseArgs(args, 1)
+		if err != nil {
+			return nil, fmt.Errorf("instances(): %s", err)
+		}
+		return NewInstances(int(f[0]), d), nil
+	case "sine":
+		if len(args) != 4 {
+			return nil, fmt.Errorf("sine(): expected 4 args (low, high, period, duration), got %d", len(args))
+		}
+		low, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("sine(): bad low %q: %s", args[0], err)
+		}
+		high, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("sine(): bad high %q: %s", args[1], err)
+		}
+		period, err := time.ParseDuration(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("sine(): bad period %q: %s", args[2], err)
+		}
+		duration, err := time.ParseDuration(args[3])
+		if err != nil {
+			return nil, fmt.Errorf("sine(): bad duration %q: %s", args[3], err)
+		}
+		return NewSine(low, high, period, duration), nil
+	default:
+		return nil, fmt.Errorf("unknown schedule %q", name)
+	}
+}
+
+// parseArgs parses the leading floatCount numeric args followed by a
+// trailing duration arg, the shape shared by const/linear/step/instances.
+func parseArgs(args []string, floatCount int) ([]float64, time.Duration, error) {
+	if len(args) != floatCount+1 {
+		return nil, 0, fmt.Errorf("expected %d args, got %d", floatCount+1, len(args))
+	}
+	floats := make([]float64, floatCount)
+	for i := 0; i < floatCount; i++ {
+		f, err := strconv.ParseFloat(args[i], 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("bad numeric arg %q: %s", args[i], err)
+		}
+		floats[i] = f
+	}
+	d, err := time.ParseDuration(args[floatCount])
+	if err != nil {
+		return nil, 0, fmt.Errorf("bad duration arg %q: %s", args[floatCount], err)
+	}
+	return floats, d, nil
+}
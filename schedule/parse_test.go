@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSingle(t *testing.T) {
+	s, err := Parse("const(500,120s)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	c, ok := s.(*Const)
+	if !ok {
+		t.Fatalf("expected *Const, got %T", s)
+	}
+	if c.RPS != 500 {
+		t.Errorf("RPS = %v, want 500", c.RPS)
+	}
+	if c.Duration() != 120*time.Second {
+		t.Errorf("Duration = %v, want 120s", c.Duration())
+	}
+}
+
+func TestParseChainIsComposite(t *testing.T) {
+	s, err := Parse("linear(1,100,60s) step(100,500,50,10s) const(500,120s)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	comp, ok := s.(*Composite)
+	if !ok {
+		t.Fatalf("expected *Composite, got %T", s)
+	}
+	if len(comp.Schedules) != 3 {
+		t.Fatalf("got %d schedules, want 3", len(comp.Schedules))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"bogus(1,2s)",
+		"const(1)",
+		"const(1,2,3s)",
+		"sine(1,2,3s)",
+		"const(notanumber,2s)",
+		"const(1,notaduration)",
+		"instances(50,10s) const(500,120s)",
+		"step(10,50,0,10s)",
+		"step(10,50,-5,10s)",
+	}
+	for _, spec := range cases {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q): expected error, got nil", spec)
+		}
+	}
+}
+
+func TestParseAllowsUnboundedScheduleLast(t *testing.T) {
+	s, err := Parse("const(500,10s) instances(50,30s)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	comp, ok := s.(*Composite)
+	if !ok {
+		t.Fatalf("expected *Composite, got %T", s)
+	}
+	if len(comp.Schedules) != 2 {
+		t.Fatalf("got %d schedules, want 2", len(comp.Schedules))
+	}
+	if comp.Count() >= 0 {
+		t.Errorf("Count() = %d, want negative (trailing schedule is unbounded)", comp.Count())
+	}
+}
+
+func TestParseSine(t *testing.T) {
+	s, err := Parse("sine(10,90,30s,5m)")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sine, ok := s.(*Sine)
+	if !ok {
+		t.Fatalf("expected *Sine, got %T", s)
+	}
+	if sine.Low != 10 || sine.High != 90 || sine.Period != 30*time.Second {
+		t.Errorf("unexpected Sine: %+v", sine)
+	}
+	if sine.Duration() != 5*time.Minute {
+		t.Errorf("Duration = %v, want 5m", sine.Duration())
+	}
+}
@@ -0,0 +1,212 @@
+// Package schedule computes when ticks of load should fire, so the lance
+// loop can drive a target request rate that varies over time instead of
+// being fixed to a single linear ramp.
+package schedule
+
+import (
+	"math"
+	"time"
+)
+
+// Schedule produces ticks. Next(i) is the time offset from the start of
+// the run at which the ith tick (1-indexed, i >= 1) should fire, and Count
+// reports how many ticks the schedule intends to issue in total. A
+// negative Count means the schedule is unbounded by tick count and relies
+// on Bounded (or the caller's context) to know when to stop.
+type Schedule interface {
+	Next(i int) time.Duration
+	Count() int
+}
+
+// Bounded is optionally implemented by Schedules that carry their own
+// wall-clock duration, so callers can cap schedules whose Count is
+// unbounded, and so Composite can offset the schedules that follow them.
+type Bounded interface {
+	Duration() time.Duration
+}
+
+// Const generates ticks at a fixed RPS for a fixed duration.
+type Const struct {
+	RPS      float64
+	duration time.Duration
+}
+
+// NewConst creates a Const schedule.
+func NewConst(rps float64, duration time.Duration) *Const {
+	return &Const{RPS: rps, duration: duration}
+}
+
+// Count implements Schedule.
+func (c *Const) Count() int {
+	return int(c.RPS * c.duration.Seconds())
+}
+
+// Next implements Schedule.
+func (c *Const) Next(i int) time.Duration {
+	return time.Duration(float64(i) / c.RPS * float64(time.Second))
+}
+
+// Duration implements Bounded.
+func (c *Const) Duration() time.Duration { return c.duration }
+
+// Linear ramps the tick rate linearly from Low RPS to High RPS over
+// Duration. It uses the closed-form solution for the tick time of a
+// linearly increasing rate function, ported from the original top-level
+// Linear/Lancer.
+type Linear struct {
+	low, high                     float64
+	duration                      time.Duration
+	lowSq, slope, durationSeconds float64
+}
+
+// NewLinear creates a Linear schedule, precomputing the constants its
+// closed-form tick equation needs.
+func NewLinear(low, high float64, duration time.Duration) *Linear {
+	durationSeconds := duration.Seconds()
+	return &Linear{
+		low:             low,
+		high:            high,
+		duration:        duration,
+		lowSq:           low * low,
+		slope:           (high - low) / durationSeconds,
+		durationSeconds: durationSeconds,
+	}
+}
+
+// Count implements Schedule.
+func (l *Linear) Count() int {
+	return int((l.high + l.low) * l.durationSeconds / 2)
+}
+
+// Next implements Schedule.
+func (l *Linear) Next(i int) time.Duration {
+	if l.slope == 0 {
+		return time.Duration(float64(i*int(time.Second)) / l.low)
+	}
+	ret := (math.Sqrt(l.lowSq+2*l.slope*float64(i)) - l.low) / l.slope
+	return time.Duration(ret * float64(time.Second))
+}
+
+// Duration implements Bounded.
+func (l *Linear) Duration() time.Duration { return l.duration }
+
+// Step increases the tick rate in discrete plateaus: it starts at Low RPS
+// and, after every StepWidth, increases the rate by StepSize RPS until it
+// reaches High.
+type Step struct {
+	Low, High, StepSize float64
+	StepWidth           time.Duration
+}
+
+// plateaus returns the RPS of each plateau in order.
+func (s *Step) plateaus() []float64 {
+	var rates []float64
+	for r := s.Low; r < s.High; r += s.StepSize {
+		rates = append(rates, r)
+	}
+	return append(rates, s.High)
+}
+
+// Count implements Schedule.
+func (s *Step) Count() int {
+	total := 0
+	for _, r := range s.plateaus() {
+		total += int(r * s.StepWidth.Seconds())
+	}
+	return total
+}
+
+// Next implements Schedule.
+func (s *Step) Next(i int) time.Duration {
+	var elapsed time.Duration
+	remaining := i
+	for _, r := range s.plateaus() {
+		n := int(r * s.StepWidth.Seconds())
+		if remaining <= n {
+			return elapsed + time.Duration(float64(remaining)/r*float64(time.Second))
+		}
+		remaining -= n
+		elapsed += s.StepWidth
+	}
+	return elapsed
+}
+
+// Duration implements Bounded.
+func (s *Step) Duration() time.Duration {
+	return time.Duration(len(s.plateaus())) * s.StepWidth
+}
+
+// Instances drives load by concurrency rather than RPS: every tick fires
+// immediately, so the only pacing comes from how fast the worker pool can
+// actually send and receive requests. Count is unbounded (-1); Duration
+// caps how long the schedule runs.
+type Instances struct {
+	N        int
+	duration time.Duration
+}
+
+// NewInstances creates an Instances schedule.
+func NewInstances(n int, duration time.Duration) *Instances {
+	return &Instances{N: n, duration: duration}
+}
+
+// Count implements Schedule. It is unbounded because concurrency, not a
+// fixed tick count, drives this schedule.
+func (in *Instances) Count() int {
+	return -1
+}
+
+// Next implements Schedule; every tick is due immediately.
+func (in *Instances) Next(i int) time.Duration {
+	return 0
+}
+
+// Duration implements Bounded.
+func (in *Instances) Duration() time.Duration { return in.duration }
+
+// Sine varies the tick rate sinusoidally between Low and High RPS with the
+// given Period, for Duration.
+type Sine struct {
+	Low, High float64
+	Period    time.Duration
+	duration  time.Duration
+}
+
+// NewSine creates a Sine schedule.
+func NewSine(low, high float64, period, duration time.Duration) *Sine {
+	return &Sine{Low: low, High: high, Period: period, duration: duration}
+}
+
+func (s *Sine) mid() float64 { return (s.Low + s.High) / 2 }
+func (s *Sine) amp() float64 { return (s.High - s.Low) / 2 }
+
+// cumulative returns the expected number of ticks fired by time t
+// (seconds), i.e. the integral of the sinusoidal rate function.
+func (s *Sine) cumulative(t float64) float64 {
+	w := 2 * math.Pi / s.Period.Seconds()
+	return s.mid()*t - s.amp()/w*(math.Cos(w*t)-1)
+}
+
+// Count implements Schedule.
+func (s *Sine) Count() int {
+	return int(s.cumulative(s.duration.Seconds()))
+}
+
+// Next implements Schedule. There is no closed form for the inverse of a
+// sinusoidal rate function, so it is found by binary search.
+func (s *Sine) Next(i int) time.Duration {
+	lo, hi := 0.0, s.duration.Seconds()
+	target := float64(i)
+	for n := 0; n < 60; n++ {
+		mid := (lo + hi) / 2
+		if s.cumulative(mid) < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return time.Duration(hi * float64(time.Second))
+}
+
+// Duration implements Bounded.
+func (s *Sine) Duration() time.Duration { return s.duration }
@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConstSchedule(t *testing.T) {
+	c := NewConst(100, 10*time.Second)
+	if got, want := c.Count(), 1000; got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+	if got, want := c.Next(100), time.Second; got != want {
+		t.Errorf("Next(100) = %v, want %v", got, want)
+	}
+}
+
+func TestLinearScheduleFlat(t *testing.T) {
+	// low == high degenerates to a constant rate; Next should use the
+	// linear-in-i shortcut rather than the closed-form ramp equation.
+	l := NewLinear(50, 50, 10*time.Second)
+	if got, want := l.Next(50), time.Second; got != want {
+		t.Errorf("Next(50) = %v, want %v", got, want)
+	}
+}
+
+func TestLinearScheduleRamp(t *testing.T) {
+	l := NewLinear(0, 100, 10*time.Second)
+	if got, want := l.Next(0), time.Duration(0); got != want {
+		t.Errorf("Next(0) = %v, want %v", got, want)
+	}
+	last := l.Next(l.Count())
+	if last <= 0 || last > 11*time.Second {
+		t.Errorf("Next(Count()) = %v, expected roughly within the 10s ramp", last)
+	}
+}
+
+func TestStepSchedule(t *testing.T) {
+	s := &Step{Low: 10, High: 30, StepSize: 10, StepWidth: time.Second}
+	// plateaus are 10, 20, 30 RPS for 1s each: 10+20+30 = 60 ticks.
+	if got, want := s.Count(), 60; got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+	if got, want := s.Duration(), 3*time.Second; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+	// the 11th tick is the first one of the second (20 RPS) plateau, 1s in
+	// plus 1/20s for its own spacing.
+	if got, want := s.Next(11), time.Second+50*time.Millisecond; got != want {
+		t.Errorf("Next(11) = %v, want %v", got, want)
+	}
+}
+
+func TestInstancesScheduleIsUnbounded(t *testing.T) {
+	in := NewInstances(50, 5*time.Second)
+	if in.Count() >= 0 {
+		t.Errorf("Count() = %d, want negative (unbounded)", in.Count())
+	}
+	if got, want := in.Next(1), time.Duration(0); got != want {
+		t.Errorf("Next(1) = %v, want %v (every tick fires immediately)", got, want)
+	}
+	if in.Duration() != 5*time.Second {
+		t.Errorf("Duration() = %v, want 5s", in.Duration())
+	}
+}
+
+func TestSineScheduleFlatDegeneratesToConstant(t *testing.T) {
+	// Low == High collapses the sine wave's amplitude to 0, so cumulative
+	// ticks should grow linearly just like a Const schedule at that RPS.
+	s := NewSine(50, 50, 10*time.Second, 20*time.Second)
+	want := time.Duration(float64(time.Second) * 100 / 50) // tick 100 at a flat 50 RPS
+	got := s.Next(100)
+	if diff := got - want; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("Next(100) = %v, want ~%v", got, want)
+	}
+}
+
+func TestSineScheduleNextIsMonotonic(t *testing.T) {
+	s := NewSine(10, 90, 30*time.Second, 5*time.Minute)
+	count := s.Count()
+	if count <= 0 {
+		t.Fatalf("Count() = %d, want positive", count)
+	}
+	prev := time.Duration(-1)
+	for _, i := range []int{1, count / 4, count / 2, 3 * count / 4, count} {
+		got := s.Next(i)
+		if got <= prev {
+			t.Errorf("Next(%d) = %v is not after the previous tick (%v): Next must be monotonic", i, got, prev)
+		}
+		prev = got
+	}
+	if last := s.Next(count); last > s.Duration()+time.Second {
+		t.Errorf("Next(Count()) = %v, expected within the %v window", last, s.Duration())
+	}
+}
+
+func TestSineScheduleNextInvertsCumulative(t *testing.T) {
+	// Next(i) is found by binary-searching cumulative() for the time at
+	// which it equals i; feeding that time back into cumulative() should
+	// recover i, which is the only way to check the binary search
+	// actually converged rather than e.g. being off by one bucket.
+	s := NewSine(20, 100, 15*time.Second, time.Minute)
+	for _, i := range []int{1, 10, 50, 100} {
+		tickTime := s.Next(i)
+		got := s.cumulative(tickTime.Seconds())
+		if diff := got - float64(i); diff < -0.01 || diff > 0.01 {
+			t.Errorf("cumulative(Next(%d).Seconds()) = %v, want ~%d", i, got, i)
+		}
+	}
+}
+
+func TestCompositeChainsSchedulesBackToBack(t *testing.T) {
+	first := NewConst(10, time.Second)  // 10 ticks, offset 0s
+	second := NewConst(20, time.Second) // 20 ticks, offset 1s
+	c := &Composite{Schedules: []Schedule{first, second}}
+
+	if got, want := c.Count(), 30; got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+	if got, want := c.Duration(), 2*time.Second; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+	// tick 10 is still in `first`.
+	if got, want := c.Next(10), first.Next(10); got != want {
+		t.Errorf("Next(10) = %v, want %v", got, want)
+	}
+	// tick 11 is the first tick of `second`, offset by first's 1s duration.
+	if got, want := c.Next(11), time.Second+second.Next(1); got != want {
+		t.Errorf("Next(11) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+// Package transport provides http.RoundTripper wrappers used to exercise
+// lancer's own handling of bad networks.
+package transport
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"regexp"
+	"syscall"
+	"time"
+)
+
+// FaultyTransport wraps a RoundTripper and injects synthetic failures and
+// latency, so the rest of lancer (missed-tick handling, worker pool
+// sizing, reporter percentile math) can be validated against adversarial
+// network conditions without needing an actually flaky upstream.
+type FaultyTransport struct {
+	// Base is the underlying RoundTripper; defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+
+	// FailRate is the probability (0..1) that a request is answered with
+	// a synthetic 5xx or a connection-reset error instead of being sent.
+	FailRate float64
+	// LatencyInject adds an extra sleep sampled uniformly from
+	// [0, LatencyInject) before every request fault injection applies to,
+	// ahead of the drop and fail decisions (so dropped requests get it too).
+	LatencyInject time.Duration
+	// DropRate is the probability (0..1) that a request hangs until its
+	// context is canceled, simulating a server that never responds.
+	DropRate float64
+
+	// HostFilter and PathFilter, if set, restrict fault injection to
+	// requests whose host/path match; nil means every request is subject
+	// to it.
+	HostFilter, PathFilter *regexp.Regexp
+
+	// Rand supplies randomness; defaults to rand.Float64 if nil. Useful
+	// to make tests of FaultyTransport itself deterministic.
+	Rand *rand.Rand
+}
+
+func (t *FaultyTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *FaultyTransport) float64() float64 {
+	if t.Rand != nil {
+		return t.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+func (t *FaultyTransport) applies(req *http.Request) bool {
+	if t.HostFilter != nil && !t.HostFilter.MatchString(req.URL.Host) {
+		return false
+	}
+	if t.PathFilter != nil && !t.PathFilter.MatchString(req.URL.Path) {
+		return false
+	}
+	return true
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FaultyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.applies(req) {
+		return t.base().RoundTrip(req)
+	}
+
+	if t.LatencyInject > 0 {
+		select {
+		case <-time.After(time.Duration(t.float64() * float64(t.LatencyInject))):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if t.DropRate > 0 && t.float64() < t.DropRate {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+
+	if t.FailRate > 0 && t.float64() < t.FailRate {
+		if t.float64() < 0.5 {
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+		}
+		return &http.Response{
+			Status:     "500 Internal Server Error (injected)",
+			StatusCode: http.StatusInternalServerError,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			Request:    req,
+		}, nil
+	}
+
+	return t.base().RoundTrip(req)
+}
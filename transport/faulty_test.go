@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func newGetRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	return req
+}
+
+func TestFaultyTransportPassesThroughWithoutFaultRates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ft := &FaultyTransport{Base: http.DefaultTransport}
+	resp, err := ft.RoundTrip(newGetRequest(t, srv.URL))
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestFaultyTransportFailRateAlwaysInjectsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request should have been answered with a synthetic failure, not forwarded")
+	}))
+	defer srv.Close()
+
+	ft := &FaultyTransport{Base: http.DefaultTransport, FailRate: 1}
+	resp, err := ft.RoundTrip(newGetRequest(t, srv.URL))
+	if err != nil {
+		return // a synthetic connection-reset error is also a valid outcome
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestFaultyTransportDropRateHangsUntilContextDone(t *testing.T) {
+	ft := &FaultyTransport{Base: http.DefaultTransport, DropRate: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req := newGetRequest(t, "http://example.invalid")
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	_, err := ft.RoundTrip(req)
+	if err == nil {
+		t.Fatal("expected an error once the context was done")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("returned after %v, expected to block until the context deadline", elapsed)
+	}
+}
+
+func TestFaultyTransportFilters(t *testing.T) {
+	var forwarded bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ft := &FaultyTransport{
+		Base:       http.DefaultTransport,
+		FailRate:   1,
+		PathFilter: regexp.MustCompile(`^/only-this-path$`),
+	}
+
+	// the request's path doesn't match PathFilter, so fault injection
+	// should not apply and the request should reach the real server.
+	if _, err := ft.RoundTrip(newGetRequest(t, srv.URL+"/other")); err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if !forwarded {
+		t.Fatal("expected the request to be forwarded to the base transport")
+	}
+}